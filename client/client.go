@@ -18,8 +18,14 @@ type Client struct {
 	Headers http.Header
 }
 
-func New(endpoint string) *Client {
-	return &Client{Endpoint: endpoint, HTTP: http.DefaultClient, Headers: make(http.Header)}
+// New creates a client for endpoint; use WithClientCertificate to
+// authenticate with mutual TLS and WithTokenSource for refreshed bearer tokens.
+func New(endpoint string, opts ...Option) *Client {
+	c := &Client{Endpoint: endpoint, HTTP: http.DefaultClient, Headers: make(http.Header)}
+	if o := newOptions(opts); o.configured() {
+		c.HTTP = &http.Client{Transport: o.transport()}
+	}
+	return c
 }
 
 type rpcRequest struct {
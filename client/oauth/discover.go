@@ -0,0 +1,263 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+)
+
+// Discover probes endpoint without credentials and follows the
+// resource_metadata parameter of the 401 WWW-Authenticate challenge to the
+// agent's protected resource metadata (RFC 9728 section 5). Without the
+// parameter the well-known location of endpoint is tried.
+func Discover(ctx context.Context, httpClient *http.Client, endpoint string) (*schema.ProtectedResourceMetadata, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(`{"jsonrpc":"2.0","id":0,"method":"tasks/get","params":{}}`))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil, fmt.Errorf("oauth: discovery expected 401 from %s, got %d", endpoint, resp.StatusCode)
+	}
+	metadataURL := ""
+	for _, challenge := range resp.Header.Values("WWW-Authenticate") {
+		if metadataURL = ChallengeParam(challenge, "resource_metadata"); metadataURL != "" {
+			break
+		}
+	}
+	if metadataURL == "" {
+		return DiscoverResource(ctx, httpClient, endpoint)
+	}
+	metadata, err := FetchMetadata(ctx, httpClient, metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	// RFC 9728 section 3.3 asks for the request URL itself; an A2A agent
+	// protects every route under its resource, so a prefix is accepted too.
+	if !coversResource(metadata.Resource, endpoint) {
+		return nil, fmt.Errorf("oauth: resource metadata for %q does not cover %s", metadata.Resource, endpoint)
+	}
+	return metadata, nil
+}
+
+// DiscoverResource fetches the metadata of resource from its well-known,
+// path-suffixed location and checks that it describes resource.
+func DiscoverResource(ctx context.Context, httpClient *http.Client, resource string) (*schema.ProtectedResourceMetadata, error) {
+	metadataURL, err := MetadataURL(resource)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := FetchMetadata(ctx, httpClient, metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(metadata.Resource, "/") != strings.TrimSuffix(resource, "/") {
+		return nil, fmt.Errorf("oauth: resource metadata describes %q, not %q", metadata.Resource, resource)
+	}
+	return metadata, nil
+}
+
+// MetadataURL inserts the well-known path between the host and path of
+// resource, e.g. https://a.example/tenants/x ->
+// https://a.example/.well-known/oauth-protected-resource/tenants/x.
+func MetadataURL(resource string) (string, error) {
+	return wellKnownURL(resource, schema.ProtectedResourceMetadataPath)
+}
+
+func wellKnownURL(identifier, wellKnown string) (string, error) {
+	u, err := url.Parse(identifier)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("oauth: invalid identifier %q", identifier)
+	}
+	u.Path = wellKnown + strings.TrimSuffix(u.Path, "/")
+	u.RawPath, u.Fragment = "", ""
+	return u.String(), nil
+}
+
+func coversResource(resource, endpoint string) bool {
+	resource = strings.TrimSuffix(resource, "/")
+	return resource != "" && (endpoint == resource || strings.HasPrefix(endpoint, resource+"/"))
+}
+
+// VerifySignedMetadata verifies metadata.SignedMetadata with keyFunc and
+// returns the metadata with the signed values taking precedence, as RFC 9728
+// section 2.2 requires. Metadata without a signature is rejected.
+func VerifySignedMetadata(ctx context.Context, metadata *schema.ProtectedResourceMetadata, keyFunc jwt.KeyFunc) (*schema.ProtectedResourceMetadata, error) {
+	if metadata.SignedMetadata == "" {
+		return nil, errors.New("oauth: metadata is not signed")
+	}
+	_, claims, err := jwt.Parse(ctx, metadata.SignedMetadata, keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: signed metadata: %w", err)
+	}
+	if claims.String("iss") == "" {
+		return nil, errors.New("oauth: signed metadata has no iss")
+	}
+	merged := map[string]interface{}{}
+	data, _ := json.Marshal(metadata)
+	_ = json.Unmarshal(data, &merged)
+	for key, value := range claims {
+		merged[key] = value
+	}
+	data, _ = json.Marshal(merged)
+	verified := &schema.ProtectedResourceMetadata{}
+	if err = json.Unmarshal(data, verified); err != nil {
+		return nil, fmt.Errorf("oauth: signed metadata: %w", err)
+	}
+	verified.SignedMetadata = metadata.SignedMetadata
+	return verified, nil
+}
+
+// AuthorizationServerMetadata is the subset of RFC 8414 metadata the
+// clients and authenticators use.
+type AuthorizationServerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint         string   `json:"token_endpoint,omitempty"`
+	IntrospectionEndpoint string   `json:"introspection_endpoint,omitempty"`
+	RevocationEndpoint    string   `json:"revocation_endpoint,omitempty"`
+	JWKSURI               string   `json:"jwks_uri,omitempty"`
+	GrantTypesSupported   []string `json:"grant_types_supported,omitempty"`
+	ScopesSupported       []string `json:"scopes_supported,omitempty"`
+}
+
+// FetchAuthorizationServer retrieves the RFC 8414 metadata of issuer, falling
+// back to OpenID Connect discovery.
+func FetchAuthorizationServer(ctx context.Context, httpClient *http.Client, issuer string) (*AuthorizationServerMetadata, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	var lastErr error
+	for _, wellKnown := range []string{"/.well-known/oauth-authorization-server", "/.well-known/openid-configuration"} {
+		metadataURL, err := wellKnownURL(issuer, wellKnown)
+		if err != nil {
+			return nil, err
+		}
+		metadata := &AuthorizationServerMetadata{}
+		if lastErr = getJSON(ctx, httpClient, metadataURL, metadata); lastErr != nil {
+			continue
+		}
+		if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+			return nil, fmt.Errorf("oauth: authorization server metadata issuer %q, want %q", metadata.Issuer, issuer)
+		}
+		return metadata, nil
+	}
+	return nil, lastErr
+}
+
+// TokenEndpoint resolves the token endpoint of the first authorization
+// server of metadata that is listed in trusted. Resource metadata is served
+// unauthenticated by the agent, so only issuers the caller trusts, and their
+// token endpoints, are accepted, and both must use https.
+func TokenEndpoint(ctx context.Context, httpClient *http.Client, metadata *schema.ProtectedResourceMetadata, trusted []string) (string, error) {
+	for _, issuer := range metadata.AuthorizationServers {
+		if !trustedIssuer(issuer, trusted) {
+			continue
+		}
+		if !isHTTPS(issuer) {
+			return "", fmt.Errorf("oauth: authorization server %s does not use https", issuer)
+		}
+		server, err := FetchAuthorizationServer(ctx, httpClient, issuer)
+		if err != nil {
+			return "", err
+		}
+		if server.TokenEndpoint == "" {
+			return "", fmt.Errorf("oauth: authorization server %s has no token_endpoint", server.Issuer)
+		}
+		if !isHTTPS(server.TokenEndpoint) {
+			return "", fmt.Errorf("oauth: token endpoint %s does not use https", server.TokenEndpoint)
+		}
+		return server.TokenEndpoint, nil
+	}
+	return "", fmt.Errorf("oauth: resource metadata names no trusted authorization server (got %v)", metadata.AuthorizationServers)
+}
+
+func trustedIssuer(issuer string, trusted []string) bool {
+	for _, candidate := range trusted {
+		if strings.TrimSuffix(candidate, "/") == strings.TrimSuffix(issuer, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPS(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+// FetchMetadata retrieves protected resource metadata from metadataURL.
+func FetchMetadata(ctx context.Context, httpClient *http.Client, metadataURL string) (*schema.ProtectedResourceMetadata, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	metadata := &schema.ProtectedResourceMetadata{}
+	if err := getJSON(ctx, httpClient, metadataURL, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth: %s: status %d", target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("oauth: decode %s: %w", target, err)
+	}
+	return nil
+}
+
+// ChallengeParam returns the value of auth-param name in a Bearer challenge,
+// e.g. resource_metadata in `Bearer resource_metadata="https://...", scope="a b"`.
+func ChallengeParam(challenge, name string) string {
+	scheme, params, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	for params != "" {
+		var key, value string
+		key, params, _ = strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		key = strings.TrimSpace(key)
+		params = strings.TrimLeft(params, " ")
+		if strings.HasPrefix(params, `"`) {
+			end := strings.Index(params[1:], `"`)
+			if end < 0 {
+				return ""
+			}
+			value, params = params[1:end+1], params[end+2:]
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+			value = strings.TrimSpace(value)
+		}
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+)
+
+func TestDiscover(t *testing.T) {
+	var issuer string
+	as := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/oauth-authorization-server" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(AuthorizationServerMetadata{Issuer: issuer, TokenEndpoint: issuer + "/token"})
+	}))
+	defer as.Close()
+	issuer = as.URL
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keys, _ := jwt.NewKeySet(jwt.SigningKey{ID: "meta", Key: key})
+	mux := http.NewServeMux()
+	svc := &auth.Service{Policy: &auth.Policy{
+		Metadata: &auth.ProtectedResourceMetadata{ScopesSupported: []string{"default"}},
+		Resources: map[string]*auth.ProtectedResourceMetadata{
+			"/tenants/acme": {AuthorizationServers: []string{as.URL}, ScopesSupported: []string{"acme:tasks"}},
+		},
+		MetadataKeys: keys,
+	}}
+	svc.RegisterHandlers(mux)
+	mux.Handle("/", svc.Middleware(http.NotFoundHandler()))
+	agent := httptest.NewServer(mux)
+	defer agent.Close()
+	svc.Policy.Metadata.Resource = agent.URL
+	svc.Policy.Resources["/tenants/acme"].Resource = agent.URL + "/tenants/acme"
+	ctx := context.Background()
+
+	// the challenge of a tenant route points at its path-suffixed metadata
+	metadata, err := Discover(ctx, nil, agent.URL+"/tenants/acme/rpc")
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if metadata.Resource != agent.URL+"/tenants/acme" || len(metadata.ScopesSupported) != 1 || metadata.ScopesSupported[0] != "acme:tasks" {
+		t.Fatalf("metadata=%+v", metadata)
+	}
+	verified, err := VerifySignedMetadata(ctx, metadata, keys.KeyFunc())
+	if err != nil || verified.Resource != metadata.Resource || len(verified.AuthorizationServers) != 1 {
+		t.Fatalf("verified=%+v err=%v", verified, err)
+	}
+	tampered := *metadata
+	tampered.SignedMetadata = metadata.SignedMetadata[:len(metadata.SignedMetadata)-4] + "AAAA"
+	if _, err = VerifySignedMetadata(ctx, &tampered, keys.KeyFunc()); err == nil {
+		t.Fatal("tampered signature accepted")
+	}
+	// client credentials only go to trusted https authorization servers
+	testCases := []struct {
+		description string
+		servers     []string
+		trusted     []string
+		expectError bool
+	}{
+		{description: "trusted", servers: []string{as.URL}, trusted: []string{as.URL + "/"}},
+		{description: "untrusted first", servers: []string{"https://evil.example", as.URL}, trusted: []string{as.URL}},
+		{description: "untrusted only", servers: []string{"https://evil.example"}, trusted: []string{as.URL}, expectError: true},
+		{description: "plain http", servers: []string{"http://as.example"}, trusted: []string{"http://as.example"}, expectError: true},
+		{description: "no allow-list", servers: []string{as.URL}, expectError: true},
+	}
+	for _, testCase := range testCases {
+		md := &schema.ProtectedResourceMetadata{AuthorizationServers: testCase.servers, TokenEndpoint: "https://evil.example/token"}
+		tokenURL, err := TokenEndpoint(ctx, as.Client(), md, testCase.trusted)
+		if testCase.expectError {
+			if err == nil {
+				t.Fatalf("%s: token endpoint=%q", testCase.description, tokenURL)
+			}
+			continue
+		}
+		if err != nil || tokenURL != as.URL+"/token" {
+			t.Fatalf("%s: token endpoint=%q err=%v", testCase.description, tokenURL, err)
+		}
+	}
+
+	// well-known lookups check the resource identifier
+	if metadata, err = DiscoverResource(ctx, nil, agent.URL); err != nil || metadata.Resource != agent.URL || metadata.BearerMethodsSupported[0] != "header" {
+		t.Fatalf("root metadata=%+v err=%v", metadata, err)
+	}
+	if _, err = DiscoverResource(ctx, nil, agent.URL+"/tenants/other"); err == nil {
+		t.Fatal("unknown resource discovered")
+	}
+	if metadataURL, _ := MetadataURL("https://a.example/tenants/x/"); metadataURL != "https://a.example/.well-known/oauth-protected-resource/tenants/x" {
+		t.Fatalf("metadata URL=%s", metadataURL)
+	}
+}
+
+func TestChallengeParam(t *testing.T) {
+	var testCases = []struct {
+		description string
+		challenge   string
+		name        string
+		expected    string
+	}{
+		{description: "quoted", challenge: `Bearer resource_metadata="https://a/.well-known/oauth-protected-resource", scope="a b"`, name: "resource_metadata", expected: "https://a/.well-known/oauth-protected-resource"},
+		{description: "second param", challenge: `Bearer resource_metadata="https://a", scope="a b"`, name: "scope", expected: "a b"},
+		{description: "token", challenge: `Bearer realm=agent, error="invalid_token"`, name: "realm", expected: "agent"},
+		{description: "other scheme", challenge: `Basic realm="agent"`, name: "realm", expected: ""},
+		{description: "missing", challenge: `Bearer error="invalid_token"`, name: "resource_metadata", expected: ""},
+	}
+	for _, testCase := range testCases {
+		if actual := ChallengeParam(testCase.challenge, testCase.name); actual != testCase.expected {
+			t.Errorf("%s: got %q, want %q", testCase.description, actual, testCase.expected)
+		}
+	}
+}
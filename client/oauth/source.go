@@ -0,0 +1,314 @@
+// Package oauth provides OAuth 2.0 token sources for the A2A clients:
+// client credentials, refresh token and RFC 8693 token exchange grants.
+// Tokens are cached until shortly before they expire; pass a Source to
+// client.WithTokenSource to authorize every request.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/a2a-protocol/client"
+)
+
+// Grant types and token types used by the sources.
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+	GrantTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeIDToken     = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// DefaultExpiryDelta is how long before expiry a cached token is refreshed.
+const DefaultExpiryDelta = 30 * time.Second
+
+// Config describes the token endpoint and client registration.
+type Config struct {
+	// TokenURL is the token endpoint. When empty it is discovered from
+	// DiscoveryURL on first use, see Discover and TokenEndpoint.
+	TokenURL string
+	// DiscoveryURL is the agent endpoint probed for a 401 challenge carrying
+	// resource_metadata.
+	DiscoveryURL string
+	// AuthorizationServers lists the issuers trusted during discovery; the
+	// client secret is only sent to their https token endpoints. Required
+	// with DiscoveryURL.
+	AuthorizationServers []string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Resource and Audience identify the target service (RFC 8707, RFC 8693).
+	Resource string
+	Audience string
+	// AuthInBody sends client credentials as form fields instead of HTTP Basic.
+	AuthInBody bool
+	// ExpiryDelta overrides DefaultExpiryDelta.
+	ExpiryDelta time.Duration
+	// HTTP is used for token and discovery requests; defaults to http.DefaultClient.
+	HTTP *http.Client
+}
+
+// Token is a token endpoint response.
+type Token struct {
+	AccessToken     string    `json:"access_token"`
+	TokenType       string    `json:"token_type,omitempty"`
+	RefreshToken    string    `json:"refresh_token,omitempty"`
+	ExpiresIn       int64     `json:"expires_in,omitempty"`
+	Scope           string    `json:"scope,omitempty"`
+	IssuedTokenType string    `json:"issued_token_type,omitempty"`
+	Expiry          time.Time `json:"-"`
+}
+
+// Error is an OAuth error response from the token endpoint.
+type Error struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth: %s: %s (status %d)", e.Code, e.Description, e.Status)
+	}
+	return fmt.Sprintf("oauth: %s (status %d)", e.Code, e.Status)
+}
+
+// Source obtains tokens with a grant and caches them until shortly before
+// expiry. It is safe for concurrent use and implements client.TokenSource.
+type Source struct {
+	config Config
+	grant  func(ctx context.Context, form url.Values) error
+	mu     sync.Mutex
+	token  *Token
+	// refresh holds the latest refresh token, rotated by the endpoint.
+	refresh      string
+	refreshGrant bool
+	now          func() time.Time
+}
+
+// ClientCredentials returns a source using the client_credentials grant.
+func ClientCredentials(config Config) *Source {
+	s := newSource(config)
+	s.grant = func(_ context.Context, form url.Values) error {
+		form.Set("grant_type", GrantClientCredentials)
+		return nil
+	}
+	return s
+}
+
+// RefreshToken returns a source redeeming refreshToken; a refresh token
+// rotated by the endpoint replaces it.
+func RefreshToken(config Config, refreshToken string) *Source {
+	s := newSource(config)
+	s.refresh = refreshToken
+	s.refreshGrant = true
+	s.grant = func(_ context.Context, form url.Values) error {
+		if s.refresh == "" {
+			return errors.New("oauth: no refresh token")
+		}
+		form.Set("grant_type", GrantRefreshToken)
+		form.Set("refresh_token", s.refresh)
+		return nil
+	}
+	return s
+}
+
+// Exchange configures an RFC 8693 token exchange.
+type Exchange struct {
+	// Subject supplies the token being exchanged, e.g. the caller's own token.
+	Subject client.TokenSource
+	// SubjectTokenType defaults to TokenTypeAccessToken.
+	SubjectTokenType string
+	// Actor optionally supplies a token for the acting party.
+	Actor              client.TokenSource
+	ActorTokenType     string
+	RequestedTokenType string
+}
+
+// TokenExchange returns a source exchanging the subject token for one scoped
+// to config.Resource/Audience.
+func TokenExchange(config Config, exchange Exchange) *Source {
+	s := newSource(config)
+	s.grant = func(ctx context.Context, form url.Values) error {
+		if exchange.Subject == nil {
+			return errors.New("oauth: token exchange requires a subject token")
+		}
+		subject, err := exchange.Subject.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("oauth: subject token: %w", err)
+		}
+		form.Set("grant_type", GrantTokenExchange)
+		form.Set("subject_token", subject)
+		form.Set("subject_token_type", orDefault(exchange.SubjectTokenType, TokenTypeAccessToken))
+		if exchange.Actor != nil {
+			actor, err := exchange.Actor.Token(ctx)
+			if err != nil {
+				return fmt.Errorf("oauth: actor token: %w", err)
+			}
+			form.Set("actor_token", actor)
+			form.Set("actor_token_type", orDefault(exchange.ActorTokenType, TokenTypeAccessToken))
+		}
+		if exchange.RequestedTokenType != "" {
+			form.Set("requested_token_type", exchange.RequestedTokenType)
+		}
+		return nil
+	}
+	return s
+}
+
+func newSource(config Config) *Source {
+	if config.ExpiryDelta == 0 {
+		config.ExpiryDelta = DefaultExpiryDelta
+	}
+	if config.HTTP == nil {
+		config.HTTP = http.DefaultClient
+	}
+	return &Source{config: config, now: time.Now}
+}
+
+// Token returns a cached access token, fetching a new one when it is missing
+// or about to expire. A cached refresh token is used before falling back to
+// the source's own grant.
+func (s *Source) Token(ctx context.Context) (string, error) {
+	token, err := s.Fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// Fetch is like Token but returns the full token response.
+func (s *Source) Fetch(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.valid() {
+		return s.token, nil
+	}
+	if s.config.TokenURL == "" {
+		if s.config.DiscoveryURL == "" {
+			return nil, errors.New("oauth: token URL not configured")
+		}
+		if len(s.config.AuthorizationServers) == 0 {
+			return nil, errors.New("oauth: discovery requires trusted AuthorizationServers")
+		}
+		metadata, err := Discover(ctx, s.config.HTTP, s.config.DiscoveryURL)
+		if err != nil {
+			return nil, err
+		}
+		if s.config.TokenURL, err = TokenEndpoint(ctx, s.config.HTTP, metadata, s.config.AuthorizationServers); err != nil {
+			return nil, err
+		}
+		if s.config.Resource == "" {
+			s.config.Resource = metadata.Resource
+		}
+	}
+	token, err := s.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	if token.RefreshToken != "" {
+		s.refresh = token.RefreshToken
+	}
+	return token, nil
+}
+
+// Invalidate drops the cached access token, e.g. after the agent rejected it.
+func (s *Source) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
+
+// valid reports whether the cached token can be used; callers hold mu.
+func (s *Source) valid() bool {
+	if s.token == nil || s.token.AccessToken == "" {
+		return false
+	}
+	return s.token.Expiry.IsZero() || s.now().Add(s.config.ExpiryDelta).Before(s.token.Expiry)
+}
+
+// request performs one token request, preferring a cached refresh token.
+func (s *Source) request(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	if s.refresh != "" {
+		form.Set("grant_type", GrantRefreshToken)
+		form.Set("refresh_token", s.refresh)
+	} else if err := s.grant(ctx, form); err != nil {
+		return nil, err
+	}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if s.config.Resource != "" {
+		form.Set("resource", s.config.Resource)
+	}
+	if s.config.Audience != "" {
+		form.Set("audience", s.config.Audience)
+	}
+	if s.config.AuthInBody {
+		form.Set("client_id", s.config.ClientID)
+		if s.config.ClientSecret != "" {
+			form.Set("client_secret", s.config.ClientSecret)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.config.AuthInBody && s.config.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+	resp, err := s.config.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		oauthErr := &Error{Status: resp.StatusCode}
+		if json.Unmarshal(body, oauthErr) != nil || oauthErr.Code == "" {
+			oauthErr.Code = "invalid_response"
+		}
+		if oauthErr.Code == "invalid_grant" && s.refresh != "" && !s.refreshGrant {
+			// the refresh token expired or was revoked; fall back to the source's grant
+			s.refresh = ""
+			return s.request(ctx)
+		}
+		return nil, oauthErr
+	}
+	token := &Token{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("oauth: decode token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("oauth: token response has no access_token")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = s.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/client"
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/server/auth"
+)
+
+// tokenServer issues sequential tokens and records the grants it served.
+type tokenServer struct {
+	mu          sync.Mutex
+	forms       []url.Values
+	issued      map[string]bool
+	rejectGrant string
+	expiresIn   int64
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	form := r.PostForm
+	if id, secret, ok := r.BasicAuth(); ok {
+		form.Set("basic", id+":"+secret)
+	}
+	s.forms = append(s.forms, form)
+	w.Header().Set("Content-Type", "application/json")
+	if form.Get("grant_type") == s.rejectGrant {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"expired"}`))
+		return
+	}
+	token := "at-" + string(rune('0'+len(s.forms)))
+	s.issued[token] = true
+	_ = json.NewEncoder(w).Encode(Token{AccessToken: token, TokenType: "Bearer", ExpiresIn: s.expiresIn, RefreshToken: "rt-" + token})
+}
+
+func (s *tokenServer) last() url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.forms[len(s.forms)-1]
+}
+
+func (s *tokenServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.forms)
+}
+
+func (s *tokenServer) Validate(_ context.Context, token string) (jwt.Claims, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.issued[token] {
+		return nil, errors.New("unknown token")
+	}
+	return jwt.Claims{"sub": "agent-a"}, nil
+}
+
+func TestSource(t *testing.T) {
+	tokens := &tokenServer{issued: map[string]bool{}, expiresIn: 3600}
+	asMux := http.NewServeMux()
+	asMux.Handle("/token", tokens)
+	tokenTS := httptest.NewTLSServer(asMux)
+	defer tokenTS.Close()
+	asMux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(AuthorizationServerMetadata{Issuer: tokenTS.URL, TokenEndpoint: tokenTS.URL + "/token"})
+	})
+
+	mux := http.NewServeMux()
+	svc := &auth.Service{Validator: tokens, Policy: &auth.Policy{Metadata: &auth.ProtectedResourceMetadata{AuthorizationServers: []string{tokenTS.URL}}}}
+	svc.RegisterHandlers(mux)
+	mux.Handle("/rpc", svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"id":"t1","contextId":"c1","status":{"state":"completed"}}}`))
+	})))
+	agent := httptest.NewServer(mux)
+	defer agent.Close()
+
+	// the token endpoint is discovered from the 401 challenge, then cached
+	source := ClientCredentials(Config{DiscoveryURL: agent.URL + "/rpc", AuthorizationServers: []string{tokenTS.URL}, ClientID: "agent-a", ClientSecret: "s3cret", Scopes: []string{"tasks:read"}, HTTP: tokenTS.Client()})
+	c := client.New(agent.URL+"/rpc", client.WithTokenSource(source))
+	for i := 0; i < 2; i++ {
+		if task, err := c.GetTask(context.Background(), "t1"); err != nil || task.ID != "t1" {
+			t.Fatalf("get task: %v", err)
+		}
+	}
+	if tokens.count() != 1 {
+		t.Fatalf("token requests=%d, want 1", tokens.count())
+	}
+	form := tokens.last()
+	if form.Get("grant_type") != GrantClientCredentials || form.Get("basic") != "agent-a:s3cret" || form.Get("resource") != agent.URL || form.Get("scope") != "tasks:read" {
+		t.Fatalf("client credentials form=%v", form)
+	}
+
+	// a token the agent rejects is dropped and the request retried once
+	tokens.mu.Lock()
+	tokens.issued = map[string]bool{}
+	tokens.mu.Unlock()
+	if _, err := c.GetTask(context.Background(), "t1"); err != nil {
+		t.Fatalf("retry after 401: %v", err)
+	}
+	if form = tokens.last(); tokens.count() != 2 || form.Get("grant_type") != GrantRefreshToken || form.Get("refresh_token") != "rt-at-1" {
+		t.Fatalf("refresh form=%v count=%d", form, tokens.count())
+	}
+
+	// tokens are refreshed shortly before expiry; a rejected refresh token
+	// falls back to the source's grant
+	now := time.Now()
+	source.now = func() time.Time { return now.Add(time.Hour - 10*time.Second) }
+	tokens.mu.Lock()
+	tokens.rejectGrant = GrantRefreshToken
+	tokens.mu.Unlock()
+	token, err := source.Token(context.Background())
+	if err != nil || token != "at-4" || tokens.last().Get("grant_type") != GrantClientCredentials {
+		t.Fatalf("expired token=%q err=%v form=%v", token, err, tokens.last())
+	}
+
+	// refresh-only sources surface the OAuth error
+	_, err = RefreshToken(Config{TokenURL: tokenTS.URL + "/token", ClientID: "agent-a", AuthInBody: true, HTTP: tokenTS.Client()}, "stale").Token(context.Background())
+	var oauthErr *Error
+	if !errors.As(err, &oauthErr) || oauthErr.Code != "invalid_grant" || oauthErr.Status != http.StatusBadRequest {
+		t.Fatalf("refresh error=%v", err)
+	}
+	if form = tokens.last(); form.Get("client_id") != "agent-a" || form.Get("basic") != "" {
+		t.Fatalf("client_secret_post form=%v", form)
+	}
+
+	// RFC 8693 token exchange
+	exchange := TokenExchange(Config{TokenURL: tokenTS.URL + "/token", Audience: "crm", HTTP: tokenTS.Client()}, Exchange{Subject: client.StaticToken("user-token"), Actor: client.StaticToken("agent-token"), RequestedTokenType: TokenTypeJWT})
+	if _, err = exchange.Token(context.Background()); err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	form = tokens.last()
+	expected := map[string]string{
+		"grant_type":           GrantTokenExchange,
+		"subject_token":        "user-token",
+		"subject_token_type":   TokenTypeAccessToken,
+		"actor_token":          "agent-token",
+		"requested_token_type": TokenTypeJWT,
+		"audience":             "crm",
+	}
+	for key, value := range expected {
+		if form.Get(key) != value {
+			t.Fatalf("exchange %s=%q, want %q", key, form.Get(key), value)
+		}
+	}
+}
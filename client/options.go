@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+)
+
+// Option configures Client and the stream clients.
+type Option func(*options)
+
+type options struct {
+	tlsConfig   *tls.Config
+	tokenSource TokenSource
+}
+
+// TokenSource supplies the bearer token sent with each request; it is expected
+// to cache tokens until shortly before they expire. See client/oauth.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource returning a fixed token.
+type StaticToken string
+
+// Token implements TokenSource.
+func (t StaticToken) Token(context.Context) (string, error) { return string(t), nil }
+
+// WithTokenSource sets Authorization: Bearer from source on every request. A
+// 401 response makes a source with an Invalidate method drop its token, and a
+// request that can be replayed is retried once with a fresh one.
+func WithTokenSource(source TokenSource) Option {
+	return func(o *options) { o.tokenSource = source }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) tls() *tls.Config {
+	if o.tlsConfig == nil {
+		o.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return o.tlsConfig
+}
+
+// WithTLSConfig sets the TLS configuration used to reach the agent.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = config.Clone() }
+}
+
+// WithClientCertificate presents cert for mutual TLS; load it with tls.LoadX509KeyPair.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(o *options) { o.tls().Certificates = append(o.tls().Certificates, cert) }
+}
+
+// WithRootCAs trusts pool when verifying the agent's certificate.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *options) { o.tls().RootCAs = pool }
+}
+
+// configured reports whether any option changes the default transport.
+func (o *options) configured() bool { return o.tlsConfig != nil || o.tokenSource != nil }
+
+// transport returns the base RoundTripper honouring the TLS and token options.
+func (o *options) transport() http.RoundTripper {
+	var base http.RoundTripper = http.DefaultTransport
+	if o.tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = o.tlsConfig
+		base = transport
+	}
+	if o.tokenSource == nil {
+		return base
+	}
+	return &bearerTransport{base: base, source: o.tokenSource}
+}
+
+// bearerTransport authorizes requests with tokens from source.
+type bearerTransport struct {
+	base   http.RoundTripper
+	source TokenSource
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	invalidator, ok := t.source.(interface{ Invalidate() })
+	if !ok || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+	invalidator.Invalidate()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_ = resp.Body.Close()
+	return t.send(retry)
+}
+
+func (t *bearerTransport) send(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("a2a client: token: %w", err)
+	}
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(authorized)
+}
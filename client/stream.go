@@ -38,10 +38,10 @@ type A2AStreamClient struct {
 
 // NewStreamClient connects to an SSE endpoint and returns a client.
 // headers will be attached to both the SSE handshake and message POSTs (e.g., Authorization).
-// handler receives incoming streaming events.
-func NewStreamClient(ctx context.Context, sseURL string, headers http.Header, op Operation) (*A2AStreamClient, error) {
+// handler receives incoming streaming events. opts configure TLS, e.g. a client certificate.
+func NewStreamClient(ctx context.Context, sseURL string, headers http.Header, op Operation, opts ...Option) (*A2AStreamClient, error) {
 	// Inject headers via custom http.Client roundtripper
-	rt := withHeaders(http.DefaultTransport, headers)
+	rt := withHeaders(newOptions(opts).transport(), headers)
 	hc := &http.Client{Transport: rt, Timeout: 0}
     h := &streamHandler{UpdateHandler: op}
     cli, err := ssecli.New(ctx, sseURL,
@@ -57,8 +57,8 @@ func NewStreamClient(ctx context.Context, sseURL string, headers http.Header, op
 }
 
 // NewStreamClientStreamable connects to a Streamable HTTP endpoint (single endpoint).
-func NewStreamClientStreamable(ctx context.Context, mcpURL string, headers http.Header, op Operation) (*A2AStreamClient, error) {
-    rt := withHeaders(http.DefaultTransport, headers)
+func NewStreamClientStreamable(ctx context.Context, mcpURL string, headers http.Header, op Operation, opts ...Option) (*A2AStreamClient, error) {
+    rt := withHeaders(newOptions(opts).transport(), headers)
     hc := &http.Client{Transport: rt, Timeout: 0}
     h := &streamHandler{UpdateHandler: op}
     cli, err := streamcli.New(ctx, mcpURL,
@@ -75,11 +75,11 @@ func NewStreamClientStreamable(ctx context.Context, mcpURL string, headers http.
 // It attempts a JSON-RPC POST to detect a Streamable HTTP endpoint. If the server
 // responds with a session header (Mcp-Session-Id), the Streamable client is used;
 // otherwise it falls back to SSE.
-func AutoStreamClient(ctx context.Context, streamURL string, headers http.Header, op Operation) (*A2AStreamClient, error) {
+func AutoStreamClient(ctx context.Context, streamURL string, headers http.Header, op Operation, opts ...Option) (*A2AStreamClient, error) {
     if streamURL == "" {
         return nil, fmt.Errorf("empty streamURL")
     }
-    rt := withHeaders(http.DefaultTransport, headers)
+    rt := withHeaders(newOptions(opts).transport(), headers)
     hc := &http.Client{Transport: rt, Timeout: 0}
 
     // Minimal JSON-RPC request – method intentionally generic; server may return method not found.
@@ -96,11 +96,11 @@ func AutoStreamClient(ctx context.Context, streamURL string, headers http.Header
         _ = resp.Body.Close()
         if resp.Header.Get("Mcp-Session-Id") != "" {
             // Streamable endpoint detected
-            return NewStreamClientStreamable(ctx, streamURL, headers, op)
+            return NewStreamClientStreamable(ctx, streamURL, headers, op, opts...)
         }
     }
     // Fallback to SSE
-    return NewStreamClient(ctx, streamURL, headers, op)
+    return NewStreamClient(ctx, streamURL, headers, op, opts...)
 }
 
 // SendMessage sends a non-streaming message (method: message/send) using the SSE message endpoint.
@@ -0,0 +1,321 @@
+// Package webhook receives A2A push notifications. Handler verifies each
+// request (notification token, HMAC signature and/or JWT against the agent's
+// JWKS), rejects replays, decodes the Task and dispatches it to a
+// client.UpdateHandler, per-task subscribers and pending Wait calls.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/viant/a2a-protocol/client"
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/schema/notification"
+)
+
+// maxBodySize bounds accepted notification bodies.
+const maxBodySize = 4 << 20
+
+// Option configures a Handler.
+type Option func(h *Handler)
+
+// WithToken requires the X-A2A-Notification-Token header to equal token.
+func WithToken(token string) Option {
+	return func(h *Handler) { h.token = token }
+}
+
+// WithSecret requires a valid HMAC signature keyed by the config's secret.
+func WithSecret(secret string) Option {
+	return func(h *Handler) { h.secret = secret }
+}
+
+// WithKeys requires a valid notification JWT verified with keys.
+func WithKeys(keys jwt.KeyFunc) Option {
+	return func(h *Handler) { h.keys = keys }
+}
+
+// WithJWKSURL requires a valid notification JWT verified against the JWKS at
+// url, typically "<agent>/.well-known/jwks.json".
+func WithJWKSURL(url string) Option {
+	return WithKeys(jwt.NewRemoteKeySet(url).KeyFunc())
+}
+
+// WithUpdateHandler forwards notifications as status and artifact update events.
+func WithUpdateHandler(updates client.UpdateHandler) Option {
+	return func(h *Handler) { h.updates = updates }
+}
+
+// WithMaxSkew sets how far a signed timestamp may differ from the local clock;
+// nonces are remembered for twice this window. Defaults to five minutes.
+func WithMaxSkew(d time.Duration) Option {
+	return func(h *Handler) { h.maxSkew = d }
+}
+
+// WithRetention sets how long terminal tasks are kept for late Wait calls.
+// Defaults to ten minutes.
+func WithRetention(d time.Duration) Option {
+	return func(h *Handler) { h.retention = d }
+}
+
+// Handler is an http.Handler receiving push notifications.
+type Handler struct {
+	token     string
+	secret    string
+	keys      jwt.KeyFunc
+	updates   client.UpdateHandler
+	maxSkew   time.Duration
+	retention time.Duration
+	now       func() time.Time
+
+	mu          sync.Mutex
+	nonces      map[string]time.Time
+	artifacts   map[string]int
+	subscribers map[string][]chan *schema.Task
+	waiters     map[string][]chan *schema.Task
+	completed   map[string]completedTask
+}
+
+type completedTask struct {
+	task *schema.Task
+	at   time.Time
+}
+
+// New creates a Handler. At least one of WithToken, WithSecret or WithKeys
+// must be configured; replay protection requires WithSecret or WithKeys.
+func New(options ...Option) (*Handler, error) {
+	h := &Handler{
+		maxSkew:     5 * time.Minute,
+		retention:   10 * time.Minute,
+		now:         time.Now,
+		nonces:      map[string]time.Time{},
+		artifacts:   map[string]int{},
+		subscribers: map[string][]chan *schema.Task{},
+		waiters:     map[string][]chan *schema.Task{},
+		completed:   map[string]completedTask{},
+	}
+	for _, opt := range options {
+		opt(h)
+	}
+	if h.token == "" && h.secret == "" && h.keys == nil {
+		return nil, errors.New("webhook: no verification configured")
+	}
+	return h, nil
+}
+
+// ServeHTTP answers the ownership handshake and accepts notifications.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		token := r.URL.Query().Get(notification.ValidationTokenParam)
+		if token == "" {
+			http.Error(w, "missing validation token", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(token))
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err = h.verify(r, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var task schema.Task
+		if err = json.Unmarshal(body, &task); err != nil || task.ID == "" {
+			http.Error(w, "invalid task", http.StatusBadRequest)
+			return
+		}
+		h.dispatch(r.Header.Get(notification.HeaderEvent), &task)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify checks every configured credential and rejects replayed nonces.
+func (h *Handler) verify(r *http.Request, body []byte) error {
+	now := h.now()
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(notification.HeaderNotificationToken)), []byte(h.token)) != 1 {
+		return errors.New("invalid notification token")
+	}
+	var nonces []string
+	if h.secret != "" {
+		timestamp, nonce := r.Header.Get(notification.HeaderTimestamp), r.Header.Get(notification.HeaderNonce)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || nonce == "" {
+			return errors.New("missing signature timestamp or nonce")
+		}
+		if skew := now.Sub(time.Unix(unix, 0)); skew > h.maxSkew || skew < -h.maxSkew {
+			return errors.New("signature timestamp outside allowed window")
+		}
+		if !notification.VerifySignature(h.secret, r.Header.Get(notification.HeaderSignature), timestamp, nonce, body) {
+			return errors.New("invalid signature")
+		}
+		nonces = append(nonces, "hmac:"+nonce)
+	}
+	if h.keys != nil {
+		claims, err := notification.VerifyJWT(r.Context(), r.Header.Get(notification.HeaderJWT), body, h.keys, h.maxSkew)
+		if err != nil {
+			return errors.New("invalid notification jwt")
+		}
+		if claims.ID == "" {
+			return errors.New("notification jwt missing jti")
+		}
+		nonces = append(nonces, "jwt:"+claims.ID)
+	}
+	return h.remember(now, nonces)
+}
+
+// remember records nonces, failing if any was seen within the replay window.
+func (h *Handler) remember(now time.Time, nonces []string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for nonce, seen := range h.nonces {
+		if now.Sub(seen) > 2*h.maxSkew {
+			delete(h.nonces, nonce)
+		}
+	}
+	for _, nonce := range nonces {
+		if _, ok := h.nonces[nonce]; ok {
+			return errors.New("replayed notification")
+		}
+	}
+	for _, nonce := range nonces {
+		h.nonces[nonce] = now
+	}
+	return nil
+}
+
+// Subscribe returns a channel receiving every notification for taskID. The
+// channel is closed after a terminal state or when cancel is called. Slow
+// subscribers miss updates rather than blocking delivery.
+func (h *Handler) Subscribe(taskID string) (<-chan *schema.Task, func()) {
+	ch := make(chan *schema.Task, 16)
+	h.mu.Lock()
+	h.subscribers[taskID] = append(h.subscribers[taskID], ch)
+	h.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if removeChan(h.subscribers, taskID, ch) {
+				close(ch)
+			}
+		})
+	}
+}
+
+// Wait blocks until taskID reaches a terminal state and returns the final task.
+func (h *Handler) Wait(ctx context.Context, taskID string) (*schema.Task, error) {
+	ch := make(chan *schema.Task, 1)
+	h.mu.Lock()
+	if done, ok := h.completed[taskID]; ok {
+		h.mu.Unlock()
+		return done.task, nil
+	}
+	h.waiters[taskID] = append(h.waiters[taskID], ch)
+	h.mu.Unlock()
+	select {
+	case task := <-ch:
+		return task, nil
+	case <-ctx.Done():
+		h.mu.Lock()
+		removeChan(h.waiters, taskID, ch)
+		h.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch fans task out to the update handler, subscribers and waiters.
+func (h *Handler) dispatch(event string, task *schema.Task) {
+	final := isTerminal(task.Status.State)
+	h.mu.Lock()
+	seen := h.artifacts[task.ID]
+	if final {
+		delete(h.artifacts, task.ID)
+	} else if len(task.Artifacts) > seen {
+		h.artifacts[task.ID] = len(task.Artifacts)
+	}
+	subscribers := h.subscribers[task.ID]
+	var waiters []chan *schema.Task
+	if final {
+		waiters = h.waiters[task.ID]
+		delete(h.subscribers, task.ID)
+		delete(h.waiters, task.ID)
+		h.complete(task)
+	}
+	for _, ch := range subscribers {
+		select {
+		case ch <- task:
+		default:
+		}
+		if final {
+			close(ch)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, ch := range waiters {
+		ch <- task
+	}
+	if h.updates == nil {
+		return
+	}
+	if seen > len(task.Artifacts) {
+		seen = 0
+	}
+	for i := seen; i < len(task.Artifacts); i++ {
+		h.updates.OnArtifactUpdate(schema.NewArtifactEvent(task, task.Artifacts[i], false, final && i == len(task.Artifacts)-1))
+	}
+	if event != notification.EventArtifactUpdate || final {
+		h.updates.OnStatusUpdate(schema.NewStatusEvent(task, final))
+	}
+}
+
+// complete records a terminal task and prunes expired entries; h.mu must be held.
+func (h *Handler) complete(task *schema.Task) {
+	now := h.now()
+	for id, done := range h.completed {
+		if now.Sub(done.at) > h.retention {
+			delete(h.completed, id)
+		}
+	}
+	h.completed[task.ID] = completedTask{task: task, at: now}
+}
+
+func removeChan(m map[string][]chan *schema.Task, taskID string, ch chan *schema.Task) bool {
+	list := m[taskID]
+	for i, candidate := range list {
+		if candidate == ch {
+			list = append(list[:i:i], list[i+1:]...)
+			if len(list) == 0 {
+				delete(m, taskID)
+			} else {
+				m[taskID] = list
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func isTerminal(state schema.TaskState) bool {
+	switch state {
+	case schema.TaskCompleted, schema.TaskFailed, schema.TaskCanceled:
+		return true
+	}
+	return false
+}
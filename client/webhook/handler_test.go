@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/schema/notification"
+	"github.com/viant/a2a-protocol/server/push"
+)
+
+type recorder struct {
+	mu        sync.Mutex
+	statuses  []schema.TaskState
+	artifacts int
+}
+
+func (r *recorder) OnStatusUpdate(e *schema.TaskStatusUpdateEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, e.Status.State)
+}
+
+func (r *recorder) OnArtifactUpdate(e *schema.TaskArtifactUpdateEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.artifacts++
+}
+
+func TestHandler_DeliveryAndWait(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keys, _ := jwt.NewKeySet(jwt.SigningKey{ID: "k1", Key: key})
+	updates := &recorder{}
+	handler, err := New(WithToken("tok"), WithSecret("s3cret"), WithKeys(keys.KeyFunc()), WithUpdateHandler(updates))
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	dispatcher := push.New(
+		push.WithURLValidator(&push.URLValidator{AllowedHosts: []string{"127.0.0.1"}}),
+		push.WithSigningKeys(keys),
+		push.WithOwnershipVerification(true),
+		push.WithWorkers(1),
+	)
+	defer dispatcher.Close()
+	if err = dispatcher.VerifyOwnership(context.Background(), ts.URL); err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+
+	events, cancel := handler.Subscribe("t-1")
+	defer cancel()
+	token, secret := "tok", "s3cret"
+	cfg := schema.PushNotificationConfig{ID: "c1", URL: ts.URL, Token: &token, Secret: &secret}
+	running, _ := json.Marshal(schema.Task{ID: "t-1", Status: schema.TaskStatus{State: schema.TaskRunning}})
+	done, _ := json.Marshal(schema.Task{ID: "t-1", Status: schema.TaskStatus{State: schema.TaskCompleted}, Artifacts: []schema.Artifact{{}}})
+	dispatcher.Notify(cfg, push.Notification{TaskID: "t-1", Body: running, StatusChanged: true})
+	dispatcher.Notify(cfg, push.Notification{TaskID: "t-1", Body: done, StatusChanged: true, ArtifactsChanged: true, Final: true})
+
+	ctx, cancelWait := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelWait()
+	task, err := handler.Wait(ctx, "t-1")
+	if err != nil || task.Status.State != schema.TaskCompleted {
+		t.Fatalf("wait: %+v %v", task, err)
+	}
+	var states []schema.TaskState
+	for task := range events {
+		states = append(states, task.Status.State)
+	}
+	if len(states) != 2 || states[1] != schema.TaskCompleted {
+		t.Fatalf("subscriber states=%v", states)
+	}
+	updates.mu.Lock()
+	defer updates.mu.Unlock()
+	if len(updates.statuses) != 2 || updates.artifacts != 1 {
+		t.Fatalf("update handler statuses=%v artifacts=%d", updates.statuses, updates.artifacts)
+	}
+}
+
+func TestHandler_RejectsReplayAndForgery(t *testing.T) {
+	handler, _ := New(WithSecret("s3cret"))
+	body := []byte(`{"id":"t-1","status":{"state":"running"}}`)
+	send := func(timestamp time.Time, nonce, secret string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set(notification.HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+		req.Header.Set(notification.HeaderNonce, nonce)
+		req.Header.Set(notification.HeaderSignature, notification.Sign(secret, timestamp, nonce, body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	now := time.Now()
+	testCases := []struct {
+		description string
+		timestamp   time.Time
+		nonce       string
+		secret      string
+		expect      int
+	}{
+		{description: "valid", timestamp: now, nonce: "n1", secret: "s3cret", expect: http.StatusNoContent},
+		{description: "replayed nonce", timestamp: now, nonce: "n1", secret: "s3cret", expect: http.StatusUnauthorized},
+		{description: "wrong secret", timestamp: now, nonce: "n2", secret: "other", expect: http.StatusUnauthorized},
+		{description: "stale timestamp", timestamp: now.Add(-time.Hour), nonce: "n3", secret: "s3cret", expect: http.StatusUnauthorized},
+	}
+	for _, testCase := range testCases {
+		if code := send(testCase.timestamp, testCase.nonce, testCase.secret); code != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, code, testCase.expect)
+		}
+	}
+	if _, err := New(); err == nil {
+		t.Fatalf("handler without verification should be rejected")
+	}
+}
@@ -0,0 +1,61 @@
+// Command a2a-audit-verify checks the HMAC chain of an audit log written by
+// audit.FileSink, including its rotated files:
+//
+//	A2A_AUDIT_KEY=... a2a-audit-verify -head-seq 1042 /var/log/a2a/audit.jsonl
+//
+// The key is read from -key-file or A2A_AUDIT_KEY. A log whose older files
+// were archived needs -anchor, the hash of the last archived entry. -head-seq
+// and -head, recorded outside the log, detect a truncated tail. It exits with
+// status 1 and reports the first broken entry when the log was modified.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/viant/a2a-protocol/server/audit"
+)
+
+func main() {
+	log.SetFlags(0)
+	keyFile := flag.String("key-file", "", "file holding the chain key (default $A2A_AUDIT_KEY)")
+	anchor := flag.String("anchor", "", "hash of the entry preceding the first one in the log")
+	headSeq := flag.Int64("head-seq", 0, "sequence number the log must reach")
+	head := flag.String("head", "", "hash of an entry the log must contain")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <audit log path>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	key := []byte(os.Getenv("A2A_AUDIT_KEY"))
+	if *keyFile != "" {
+		data, err := os.ReadFile(*keyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		key = []byte(strings.TrimSpace(string(data)))
+	}
+	if len(key) == 0 {
+		log.Fatal("no audit key: set -key-file or A2A_AUDIT_KEY")
+	}
+	files := audit.Files(flag.Arg(0))
+	if len(files) == 0 {
+		log.Fatalf("no audit log at %s", flag.Arg(0))
+	}
+	result, err := audit.VerifyFiles(audit.Expectations{Key: key, Anchor: *anchor, HeadSeq: *headSeq, Head: *head}, files...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if result.Entries == 0 {
+		fmt.Println("ok: log is empty")
+		return
+	}
+	fmt.Printf("ok: %d entries in %d files, seq %d..%d, head %s\n", result.Entries, len(files), result.First.Seq, result.Last.Seq, result.Last.Hash)
+}
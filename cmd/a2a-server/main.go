@@ -2,13 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/viant/a2a-protocol/schema"
 	"github.com/viant/a2a-protocol/server"
+	"github.com/viant/a2a-protocol/server/audit"
 	aauth "github.com/viant/a2a-protocol/server/auth"
 	"github.com/viant/jsonrpc"
 )
@@ -18,19 +19,23 @@ func main() {
 	rpcPath := getenv("A2A_JSONRPC_PATH", "/v1/jsonrpc")
 
     card := schema.AgentCard{
-        Name:      "example-a2a-server",
+        Name:               "example-a2a-server",
+        URL:                "/a2a",
+        PreferredTransport: schema.TransportJSONRPC,
+        AdditionalInterfaces: []schema.AgentInterface{
+            {URL: "/a2a", Transport: schema.TransportJSONRPC},
+            {URL: "/v1", Transport: schema.TransportHTTPJSON},
+        },
         Endpoints: map[string]string{
             "jsonrpc": rpcPath,
             "rest":    "/v1",
             "sse":     "/v1/message:stream",
             "streamable": "/a2a",
         },
-        Authentication: map[string]interface{}{
-            "securitySchemes": map[string]map[string]string{
-                "Bearer": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
-            },
-            "security": []map[string][]string{{"Bearer": {}}},
+        SecuritySchemes: map[string]schema.SecurityScheme{
+            "Bearer": {Type: schema.SecuritySchemeHTTP, Scheme: "bearer", BearerFormat: "JWT"},
         },
+        Security: []map[string][]string{{"Bearer": {}}},
     }
     // Spec-compliant capabilities object (also derives legacy list for compatibility)
     streaming, push, sth := true, true, false
@@ -40,6 +45,18 @@ func main() {
         StateTransitionHistory: &sth,
     })
 
+	// HTTPS with optional client certificates: a verified certificate is an
+	// alternative to the bearer token
+	tlsOptions := &aauth.TLSOptions{
+		CertFile:     os.Getenv("A2A_TLS_CERT"),
+		KeyFile:      os.Getenv("A2A_TLS_KEY"),
+		ClientCAFile: os.Getenv("A2A_TLS_CLIENT_CA"),
+	}
+	if tlsOptions.CertFile != "" && tlsOptions.ClientCAFile != "" {
+		card.SecuritySchemes["mtls"] = schema.SecurityScheme{Type: schema.SecuritySchemeMutualTLS}
+		card.Security = append(card.Security, map[string][]string{"mtls": {}})
+	}
+
 	// Build default handler with simple message/send and message/stream
 	newOps := server.WithDefaultHandler(context.Background(), func(h *server.DefaultHandler) error {
 		h.OnMessageSend = func(ctx context.Context, messages []schema.Message, contextID, taskID *string) (*schema.Task, *jsonrpc.Error) {
@@ -49,12 +66,38 @@ func main() {
 		}
 		h.OnMessageStream = func(ctx context.Context, messages []schema.Message, contextID, taskID *string) (*schema.Task, *jsonrpc.Error) {
 			t := h.NewTask(contextID)
-			go h.StreamDemo(t)
+			go h.StreamDemo(ctx, t)
 			return t, nil
 		}
 		return nil
 	})
-	srv := server.New(card, server.WithOperations(newOps))
+	options := []server.ServerOption{server.WithOperations(newOps)}
+	// Optional tamper-evident audit log, checked with a2a-audit-verify
+	if path := os.Getenv("A2A_AUDIT_LOG"); path != "" {
+		key := os.Getenv("A2A_AUDIT_KEY")
+		if key == "" {
+			log.Fatal("A2A_AUDIT_LOG requires A2A_AUDIT_KEY")
+		}
+		sink, err := audit.NewFileSink(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer sink.Close()
+		options = append(options, server.WithAudit(audit.New(sink, []byte(key)), func(entry audit.Entry, err error) {
+			log.Printf("a2a: audit %s: %v", entry.Method, err)
+		}))
+	}
+	// Browser clients: comma-separated origins allowed to call the agent
+	if origins := os.Getenv("A2A_CORS_ORIGINS"); origins != "" {
+		options = append(options, server.WithCORS(server.CORS{AllowedOrigins: strings.Split(origins, ",")}))
+	}
+	// Reverse proxies whose X-Forwarded-* headers name the public origin
+	var proxies []string
+	if value := os.Getenv("A2A_TRUSTED_PROXIES"); value != "" {
+		proxies = strings.Split(value, ",")
+		options = append(options, server.WithTrustedProxies(proxies...))
+	}
+	srv := server.New(card, options...)
 	// Inner mux with the actual endpoints
 	inner := http.NewServeMux()
 	srv.RegisterSSE(inner, "/v1")
@@ -64,23 +107,38 @@ func main() {
     // Agent card is served at the well-known location only
 
 	// Auth middleware and metadata endpoint
+	// The resource identifier defaults to the request origin
 	policy := &aauth.Policy{Metadata: &aauth.ProtectedResourceMetadata{
-		Resource:        "a2a",
+		ResourceName:    card.Name,
 		ScopesSupported: []string{"default"},
-	}}
+	}, TrustedProxies: proxies}
+	if issuer := os.Getenv("A2A_AUTH_SERVER"); issuer != "" {
+		policy.Metadata.AuthorizationServers = []string{issuer}
+	}
 	authSvc := aauth.NewService(policy)
+	// Enforce the security requirements declared in the card. The demo has no
+	// token validator, so it accepts any bearer token explicitly.
+	authSvc.Card = srv.Card
+	authSvc.AcceptAnyBearer = true
 
 	// Outer mux: metadata + agent card + middleware-wrapped inner
 	outer := http.NewServeMux()
 	authSvc.RegisterHandlers(outer)
-	outer.HandleFunc("/.well-known/agent-card.json", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(card)
-	})
+	srv.RegisterWellKnown(outer)
 	outer.Handle("/", authSvc.Middleware(inner))
+	// CORS headers on auth challenges and resource metadata as well
+	handler := srv.CORS(outer)
 
     log.Printf("A2A server listening on %s (SSE+JSON-RPC at /v1, Streamable at /a2a)", addr)
-	log.Fatal(http.ListenAndServe(addr, outer))
+	if tlsOptions.CertFile == "" {
+		log.Fatal(http.ListenAndServe(addr, handler))
+	}
+	tlsConfig, err := tlsOptions.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+	httpServer := &http.Server{Addr: addr, Handler: handler, TLSConfig: tlsConfig}
+	log.Fatal(httpServer.ListenAndServeTLS("", ""))
 }
 
 func getenv(k, d string) string {
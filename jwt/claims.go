@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExpired is returned by Validate for expired or not-yet-valid tokens.
+var ErrExpired = errors.New("jwt: token expired or not yet valid")
+
+// Expectations lists the registered claims Validate checks. Empty fields are not checked.
+type Expectations struct {
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp, nbf and iat.
+	Leeway time.Duration
+	// Now overrides the current time.
+	Now time.Time
+}
+
+// Validate checks exp, nbf and iat against the current time and, when
+// expected, iss and aud. A malformed exp, nbf or iat fails validation.
+func (c Claims) Validate(expect Expectations) error {
+	now := expect.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	exp, hasExp, err := c.NumericDate("exp")
+	if err != nil {
+		return err
+	}
+	if hasExp && now.After(exp.Add(expect.Leeway)) {
+		return ErrExpired
+	}
+	nbf, hasNbf, err := c.NumericDate("nbf")
+	if err != nil {
+		return err
+	}
+	if hasNbf && now.Add(expect.Leeway).Before(nbf) {
+		return ErrExpired
+	}
+	iat, hasIat, err := c.NumericDate("iat")
+	if err != nil {
+		return err
+	}
+	if hasIat && now.Add(expect.Leeway).Before(iat) {
+		return errors.New("jwt: token issued in the future")
+	}
+	if expect.Issuer != "" && c.String("iss") != expect.Issuer {
+		return fmt.Errorf("jwt: unexpected issuer %q", c.String("iss"))
+	}
+	if expect.Audience != "" && !c.HasAudience(expect.Audience) {
+		return fmt.Errorf("jwt: token not issued for %q", expect.Audience)
+	}
+	return nil
+}
+
+// HasAudience reports whether aud (a string or array) contains audience.
+func (c Claims) HasAudience(audience string) bool {
+	switch aud := c["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok && s == audience {
+				return true
+			}
+		}
+	case []string:
+		for _, s := range aud {
+			if s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
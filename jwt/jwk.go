@@ -0,0 +1,203 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+)
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK describes the public part of key (a public or private ECDSA P-256,
+// RSA or Ed25519 key) as a signing JWK.
+func NewJWK(kid string, key interface{}) (JWK, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return NewJWK(kid, &k.PublicKey)
+	case *rsa.PrivateKey:
+		return NewJWK(kid, &k.PublicKey)
+	case ed25519.PrivateKey:
+		return NewJWK(kid, k.Public())
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return JWK{}, errors.New("jwt: only P-256 ECDSA keys are supported")
+		}
+		x, y := make([]byte, 32), make([]byte, 32)
+		k.X.FillBytes(x)
+		k.Y.FillBytes(y)
+		return JWK{Kty: "EC", Kid: kid, Use: "sig", Alg: ES256, Crv: "P-256", X: encode(x), Y: encode(y)}, nil
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Kid: kid, Use: "sig", Alg: RS256, N: encode(k.N.Bytes()), E: encode(big.NewInt(int64(k.E)).Bytes())}, nil
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: kid, Use: "sig", Alg: EdDSA, Crv: "Ed25519", X: encode(k)}, nil
+	}
+	return JWK{}, fmt.Errorf("jwt: unsupported key type %T", key)
+}
+
+// PublicKey decodes the key material.
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("jwt: unsupported curve %q", k.Crv)
+		}
+		x, errX := decode(k.X)
+		y, errY := decode(k.Y)
+		if errX != nil || errY != nil {
+			return nil, errors.New("jwt: malformed EC key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("jwt: EC point not on curve")
+		}
+		return pub, nil
+	case "RSA":
+		n, errN := decode(k.N)
+		e, errE := decode(k.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("jwt: malformed RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		x, err := decode(k.X)
+		if err != nil || k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("jwt: malformed OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("jwt: unsupported key type %q", k.Kty)
+}
+
+// Key returns the public key with the given kid.
+func (s *JWKS) Key(kid string) (crypto.PublicKey, error) {
+	for i := range s.Keys {
+		if s.Keys[i].Kid == kid {
+			return s.Keys[i].PublicKey()
+		}
+	}
+	return nil, fmt.Errorf("jwt: unknown key %q", kid)
+}
+
+// KeySet holds the active signing key and the public keys still published for
+// verification. Rotate installs a new signing key while keeping previous
+// public keys in the JWKS until Retire removes them, so tokens signed before a
+// rotation keep verifying.
+type KeySet struct {
+	mu        sync.RWMutex
+	signing   SigningKey
+	published []JWK
+}
+
+// NewKeySet creates a key set signing with key.
+func NewKeySet(key SigningKey) (*KeySet, error) {
+	s := &KeySet{}
+	if err := s.Rotate(key); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Rotate makes key the signing key and publishes its public part.
+func (s *KeySet) Rotate(key SigningKey) error {
+	if key.ID == "" {
+		return errors.New("jwt: signing key requires an ID")
+	}
+	if _, ok := key.Key.([]byte); ok {
+		return errors.New("jwt: key set requires an asymmetric key")
+	}
+	jwk, err := NewJWK(key.ID, key.Key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, published := range s.published {
+		if published.Kid == key.ID {
+			return fmt.Errorf("jwt: key %q already published", key.ID)
+		}
+	}
+	s.signing = key
+	s.published = append(s.published, jwk)
+	return nil
+}
+
+// Retire stops publishing the public key kid. The active signing key cannot be retired.
+func (s *KeySet) Retire(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kid == s.signing.ID {
+		return fmt.Errorf("jwt: key %q is the active signing key", kid)
+	}
+	for i, published := range s.published {
+		if published.Kid == kid {
+			s.published = append(s.published[:i:i], s.published[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("jwt: unknown key %q", kid)
+}
+
+// Sign signs claims with the active key.
+func (s *KeySet) Sign(claims Claims) (string, error) {
+	s.mu.RLock()
+	key := s.signing
+	s.mu.RUnlock()
+	return Sign(key, claims)
+}
+
+// JWKS returns the published public keys.
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]JWK, len(s.published))
+	copy(keys, s.published)
+	return JWKS{Keys: keys}
+}
+
+// KeyFunc verifies tokens against the published keys.
+func (s *KeySet) KeyFunc() KeyFunc {
+	return func(_ context.Context, header Header) (interface{}, error) {
+		set := s.JWKS()
+		return set.Key(header.Kid)
+	}
+}
+
+// ServeHTTP writes the JWKS document.
+func (s *KeySet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.JWKS())
+}
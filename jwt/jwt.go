@@ -0,0 +1,254 @@
+// Package jwt implements the compact JWS/JWT and JWK subset used by the A2A
+// server and client: ES256, RS256, EdDSA and HS256 signatures built on the
+// standard library, JSON Web Key Sets and a cached remote key set.
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Supported signature algorithms.
+const (
+	ES256 = "ES256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+	HS256 = "HS256"
+)
+
+// ErrInvalidSignature is returned when a token signature does not verify.
+var ErrInvalidSignature = errors.New("jwt: invalid signature")
+
+// Header is the JOSE header of a token.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Claims holds the token payload.
+type Claims map[string]interface{}
+
+// String returns a string claim or "".
+func (c Claims) String(name string) string {
+	v, _ := c[name].(string)
+	return v
+}
+
+// Int64 returns a numeric claim, truncating fractions.
+func (c Claims) Int64(name string) (int64, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return int64(v), true
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, true
+		}
+		f, err := v.Float64()
+		return int64(f), err == nil
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// NumericDate returns a RFC 7519 NumericDate claim such as exp. The value
+// may carry a fraction of a second; ok is false when the claim is absent and
+// err is set when it is present but not a finite number.
+func (c Claims) NumericDate(name string) (t time.Time, ok bool, err error) {
+	value, ok := c[name]
+	if !ok || value == nil {
+		return time.Time{}, false, nil
+	}
+	var seconds float64
+	switch v := value.(type) {
+	case float64:
+		seconds = v
+	case json.Number:
+		if seconds, err = v.Float64(); err != nil {
+			return time.Time{}, true, fmt.Errorf("jwt: malformed %s claim", name)
+		}
+	case int64:
+		return time.Unix(v, 0), true, nil
+	case int:
+		return time.Unix(int64(v), 0), true, nil
+	default:
+		return time.Time{}, true, fmt.Errorf("jwt: malformed %s claim", name)
+	}
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return time.Time{}, true, fmt.Errorf("jwt: malformed %s claim", name)
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), true, nil
+}
+
+// SigningKey is a private key identified by ID (the JWS "kid"). Key is an
+// *ecdsa.PrivateKey (P-256), *rsa.PrivateKey, ed25519.PrivateKey or, for
+// HS256, a []byte secret.
+type SigningKey struct {
+	ID  string
+	Key crypto.PrivateKey
+}
+
+// Algorithm returns the JWS algorithm implied by the key type.
+func (k *SigningKey) Algorithm() (string, error) {
+	return algorithmFor(k.Key)
+}
+
+// Sign returns a compact JWS of claims signed with key.
+func Sign(key SigningKey, claims Claims) (string, error) {
+	alg, err := key.Algorithm()
+	if err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(Header{Alg: alg, Kid: key.ID, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := encode(header) + "." + encode(payload)
+	sig, err := sign(key.Key, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + encode(sig), nil
+}
+
+// KeyFunc returns the verification key for a token header: a public key or,
+// for HS256, the shared secret as []byte.
+type KeyFunc func(ctx context.Context, header Header) (interface{}, error)
+
+// Parse verifies token with the key returned by keyFunc and returns its header
+// and claims. The header algorithm must match the key type, so a public key
+// can never be used as an HMAC secret. Registered claims (exp, nbf, iss, aud)
+// are not checked here; see Validate.
+func Parse(ctx context.Context, token string, keyFunc KeyFunc) (Header, Claims, error) {
+	var header Header
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return header, nil, errors.New("jwt: malformed token")
+	}
+	rawHeader, err := decode(parts[0])
+	if err != nil {
+		return header, nil, fmt.Errorf("jwt: malformed header: %w", err)
+	}
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return header, nil, fmt.Errorf("jwt: malformed header: %w", err)
+	}
+	sig, err := decode(parts[2])
+	if err != nil {
+		return header, nil, fmt.Errorf("jwt: malformed signature: %w", err)
+	}
+	key, err := keyFunc(ctx, header)
+	if err != nil {
+		return header, nil, err
+	}
+	if alg, err := algorithmFor(key); err != nil || alg != header.Alg {
+		return header, nil, fmt.Errorf("jwt: unexpected algorithm %q", header.Alg)
+	}
+	if !verify(key, []byte(parts[0]+"."+parts[1]), sig) {
+		return header, nil, ErrInvalidSignature
+	}
+	rawClaims, err := decode(parts[1])
+	if err != nil {
+		return header, nil, fmt.Errorf("jwt: malformed claims: %w", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(rawClaims)))
+	decoder.UseNumber()
+	var claims Claims
+	if err = decoder.Decode(&claims); err != nil {
+		return header, nil, fmt.Errorf("jwt: malformed claims: %w", err)
+	}
+	return header, claims, nil
+}
+
+func algorithmFor(key interface{}) (string, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return "", errors.New("jwt: only P-256 ECDSA keys are supported")
+		}
+		return ES256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return "", errors.New("jwt: only P-256 ECDSA keys are supported")
+		}
+		return ES256, nil
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return RS256, nil
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return EdDSA, nil
+	case []byte:
+		return HS256, nil
+	}
+	return "", fmt.Errorf("jwt: unsupported key type %T", key)
+}
+
+func sign(key crypto.PrivateKey, input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, input), nil
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	}
+	return nil, fmt.Errorf("jwt: unsupported key type %T", key)
+}
+
+func verify(key interface{}, input, sig []byte) bool {
+	digest := sha256.Sum256(input)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(k, digest[:], r, s)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return len(k) == ed25519.PublicKeySize && ed25519.Verify(k, input, sig)
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write(input)
+		return hmac.Equal(mac.Sum(nil), sig)
+	}
+	return false
+}
+
+func encode(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func decode(s string) ([]byte, error) { return base64.RawURLEncoding.DecodeString(s) }
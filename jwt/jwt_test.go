@@ -0,0 +1,148 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignParse(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	testCases := []struct {
+		description string
+		key         SigningKey
+		alg         string
+	}{
+		{description: "ecdsa", key: SigningKey{ID: "ec", Key: ecKey}, alg: ES256},
+		{description: "rsa", key: SigningKey{ID: "rsa", Key: rsaKey}, alg: RS256},
+		{description: "ed25519", key: SigningKey{ID: "ed", Key: edKey}, alg: EdDSA},
+		{description: "hmac", key: SigningKey{ID: "hs", Key: []byte("secret")}, alg: HS256},
+	}
+	for _, testCase := range testCases {
+		token, err := Sign(testCase.key, Claims{"sub": "agent"})
+		if err != nil {
+			t.Fatalf("%s: sign: %v", testCase.description, err)
+		}
+		keyFunc := func(_ context.Context, header Header) (interface{}, error) {
+			if _, ok := testCase.key.Key.([]byte); ok {
+				return testCase.key.Key, nil
+			}
+			jwk, err := NewJWK(header.Kid, testCase.key.Key)
+			if err != nil {
+				return nil, err
+			}
+			return jwk.PublicKey()
+		}
+		header, claims, err := Parse(context.Background(), token, keyFunc)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", testCase.description, err)
+		}
+		if header.Alg != testCase.alg || header.Kid != testCase.key.ID || claims.String("sub") != "agent" {
+			t.Fatalf("%s: header=%+v claims=%v", testCase.description, header, claims)
+		}
+		if _, _, err = Parse(context.Background(), token[:len(token)-4]+"AAAA", keyFunc); err == nil {
+			t.Fatalf("%s: tampered signature accepted", testCase.description)
+		}
+	}
+}
+
+func TestParse_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwk, _ := NewJWK("k1", rsaKey)
+	// HS256 token keyed with the public JWK bytes must not verify against the RSA key
+	raw, _ := json.Marshal(jwk)
+	token, _ := Sign(SigningKey{ID: "k1", Key: raw}, Claims{"sub": "x"})
+	set := JWKS{Keys: []JWK{jwk}}
+	_, _, err := Parse(context.Background(), token, func(_ context.Context, header Header) (interface{}, error) {
+		return set.Key(header.Kid)
+	})
+	if err == nil {
+		t.Fatalf("expected algorithm mismatch to be rejected")
+	}
+}
+
+func TestKeySet_RotateAndRemote(t *testing.T) {
+	first, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys, err := NewKeySet(SigningKey{ID: "k1", Key: first})
+	if err != nil {
+		t.Fatalf("key set: %v", err)
+	}
+	var fetches int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		keys.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	remote := NewRemoteKeySet(ts.URL)
+	remote.MinRefresh = 0
+
+	oldToken, _ := keys.Sign(Claims{"n": 1})
+	if _, _, err = Parse(context.Background(), oldToken, remote.KeyFunc()); err != nil {
+		t.Fatalf("verify k1: %v", err)
+	}
+	_, second, _ := ed25519.GenerateKey(rand.Reader)
+	if err = keys.Rotate(SigningKey{ID: "k2", Key: second}); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	newToken, _ := keys.Sign(Claims{"n": 2})
+	if header, _, err := Parse(context.Background(), newToken, remote.KeyFunc()); err != nil || header.Kid != "k2" {
+		t.Fatalf("verify k2 after refresh: kid=%s err=%v", header.Kid, err)
+	}
+	if _, _, err = Parse(context.Background(), oldToken, remote.KeyFunc()); err != nil {
+		t.Fatalf("retained key should still verify: %v", err)
+	}
+	if atomic.LoadInt32(&fetches) != 2 {
+		t.Fatalf("fetches=%d, want 2 (initial + unknown kid)", fetches)
+	}
+	if err = keys.Retire("k2"); err == nil {
+		t.Fatalf("active key must not be retired")
+	}
+	if err = keys.Retire("k1"); err != nil || len(keys.JWKS().Keys) != 1 {
+		t.Fatalf("retire k1: %v keys=%d", err, len(keys.JWKS().Keys))
+	}
+}
+
+func TestClaims_Validate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	claims := Claims{"exp": json.Number("1700000100"), "nbf": json.Number("1699999900"), "iss": "idp", "aud": []interface{}{"a2a", "other"}}
+	if err := claims.Validate(Expectations{Issuer: "idp", Audience: "a2a", Now: now}); err != nil {
+		t.Fatalf("valid claims: %v", err)
+	}
+	if err := claims.Validate(Expectations{Now: now.Add(time.Hour)}); err != ErrExpired {
+		t.Fatalf("expected expiry, got %v", err)
+	}
+	if err := claims.Validate(Expectations{Audience: "nope", Now: now}); err == nil {
+		t.Fatalf("expected audience mismatch")
+	}
+
+	testCases := []struct {
+		description string
+		claims      Claims
+		expectErr   bool
+	}{
+		{description: "fractional exp", claims: Claims{"exp": json.Number("1700000000.5")}},
+		{description: "fractional exp passed", claims: Claims{"exp": json.Number("1699999999.5")}, expectErr: true},
+		{description: "float exp", claims: Claims{"exp": 1700000100.25}},
+		{description: "missing dates", claims: Claims{}},
+		{description: "string exp", claims: Claims{"exp": "1700000100"}, expectErr: true},
+		{description: "bool nbf", claims: Claims{"nbf": true}, expectErr: true},
+		{description: "malformed iat", claims: Claims{"iat": json.Number("soon")}, expectErr: true},
+	}
+	for _, testCase := range testCases {
+		err := testCase.claims.Validate(Expectations{Now: now})
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("%s: err=%v", testCase.description, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RemoteKeySet verifies tokens against a JWKS fetched from a URL. Keys are
+// cached for TTL; an unknown kid triggers a refresh (at most once per
+// MinRefresh) so rotated keys are picked up without waiting for expiry.
+type RemoteKeySet struct {
+	URL        string
+	HTTP       *http.Client
+	TTL        time.Duration
+	MinRefresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// NewRemoteKeySet creates a key set for the JWKS at url with a one hour TTL.
+func NewRemoteKeySet(url string) *RemoteKeySet {
+	return &RemoteKeySet{URL: url, TTL: time.Hour, MinRefresh: 30 * time.Second}
+}
+
+// Key returns the public key kid, fetching the JWKS when needed.
+func (r *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	age := time.Since(r.fetched)
+	if key, ok := r.keys[kid]; ok && age < r.TTL {
+		return key, nil
+	}
+	if r.keys == nil || age >= r.MinRefresh {
+		if err := r.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if key, ok := r.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("jwt: unknown key %q", kid)
+}
+
+// KeyFunc adapts the key set for Parse.
+func (r *RemoteKeySet) KeyFunc() KeyFunc {
+	return func(ctx context.Context, header Header) (interface{}, error) {
+		return r.Key(ctx, header.Kid)
+	}
+}
+
+func (r *RemoteKeySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return err
+	}
+	client := r.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwt: fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwt: fetch jwks: %s", resp.Status)
+	}
+	var set JWKS
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("jwt: decode jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i := range set.Keys {
+		if set.Keys[i].Use != "" && set.Keys[i].Use != "sig" {
+			continue
+		}
+		if key, err := set.Keys[i].PublicKey(); err == nil {
+			keys[set.Keys[i].Kid] = key
+		}
+	}
+	r.keys, r.fetched = keys, time.Now()
+	return nil
+}
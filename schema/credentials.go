@@ -0,0 +1,57 @@
+package schema
+
+// Data part keys used for in-task (secondary) authentication.
+const (
+	// CredentialRequestKey holds a CredentialRequest in the status message of
+	// an auth-required task.
+	CredentialRequestKey = "auth"
+	// CredentialKey holds a Credential in a message continuing that task.
+	CredentialKey = "credential"
+)
+
+// CredentialRequest describes the credential an auth-required task waits for.
+type CredentialRequest struct {
+	// Resource identifies what the credential grants access to.
+	Resource string   `json:"resource"`
+	Scopes   []string `json:"scopes,omitempty"`
+	// AuthorizationURI is where the user can obtain the credential.
+	AuthorizationURI string `json:"authorization_uri,omitempty"`
+	// Scheme is the expected credential type, e.g. "bearer".
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// Credential is supplied by the client to resume an auth-required task.
+type Credential struct {
+	Resource string `json:"resource,omitempty"`
+	Scheme   string `json:"scheme,omitempty"`
+	Token    string `json:"token"`
+}
+
+// String keeps the token out of logs.
+func (c Credential) String() string {
+	return "Credential{Resource:" + c.Resource + " Scheme:" + c.Scheme + " Token:[redacted]}"
+}
+
+// DataPart returns the auth-required status message for the request.
+func (r CredentialRequest) DataPart() *DataPart {
+	auth := map[string]interface{}{"resource": r.Resource, "scopes": r.Scopes}
+	if r.AuthorizationURI != "" {
+		auth["authorization_uri"] = r.AuthorizationURI
+	}
+	if r.Scheme != "" {
+		auth["scheme"] = r.Scheme
+	}
+	return &DataPart{Type: "data", Data: map[string]interface{}{CredentialRequestKey: auth}}
+}
+
+// DataPart returns the message part that supplies the credential.
+func (c Credential) DataPart() DataPart {
+	credential := map[string]interface{}{"token": c.Token}
+	if c.Resource != "" {
+		credential["resource"] = c.Resource
+	}
+	if c.Scheme != "" {
+		credential["scheme"] = c.Scheme
+	}
+	return DataPart{Type: "data", Data: map[string]interface{}{CredentialKey: credential}}
+}
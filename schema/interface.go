@@ -0,0 +1,16 @@
+package schema
+
+// Core A2A transport protocol identifiers.
+const (
+    TransportJSONRPC  = "JSONRPC"
+    TransportGRPC     = "GRPC"
+    TransportHTTPJSON = "HTTP+JSON"
+)
+
+// AgentInterface declares a URL and the transport protocol available at it.
+type AgentInterface struct {
+    // The URL where this interface is available.
+    URL string `json:"url"`
+    // The transport protocol supported at this URL (e.g. JSONRPC, HTTP+JSON).
+    Transport string `json:"transport"`
+}
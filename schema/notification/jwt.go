@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+)
+
+// Claims carried by the notification JWT.
+const (
+	ClaimTaskID   = "task_id"
+	ClaimBodyHash = "request_body_sha256"
+)
+
+// JWTClaims are the verified claims of a notification token.
+type JWTClaims struct {
+	TaskID   string
+	IssuedAt time.Time
+	// ID is the token's unique jti, usable for replay detection.
+	ID string
+}
+
+// BodyHash returns the ClaimBodyHash value for body.
+func BodyHash(body []byte) string {
+	digest := sha256.Sum256(body)
+	return hex.EncodeToString(digest[:])
+}
+
+// VerifyJWT verifies a HeaderJWT value against the agent's keys (for example
+// jwt.NewRemoteKeySet(agentURL+"/.well-known/jwks.json").KeyFunc()), checks
+// that it covers body and was issued within maxAge.
+func VerifyJWT(ctx context.Context, token string, body []byte, keys jwt.KeyFunc, maxAge time.Duration) (*JWTClaims, error) {
+	_, claims, err := jwt.Parse(ctx, token, keys)
+	if err != nil {
+		return nil, err
+	}
+	if err = claims.Validate(jwt.Expectations{Leeway: time.Minute}); err != nil {
+		return nil, err
+	}
+	issuedAt, ok, _ := claims.NumericDate("iat")
+	if !ok {
+		return nil, errors.New("notification: token missing iat")
+	}
+	if maxAge > 0 && time.Since(issuedAt) > maxAge {
+		return nil, errors.New("notification: token too old")
+	}
+	if subtle.ConstantTimeCompare([]byte(BodyHash(body)), []byte(claims.String(ClaimBodyHash))) != 1 {
+		return nil, errors.New("notification: body hash mismatch")
+	}
+	return &JWTClaims{TaskID: claims.String(ClaimTaskID), IssuedAt: issuedAt, ID: claims.String("jti")}, nil
+}
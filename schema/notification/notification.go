@@ -0,0 +1,67 @@
+// Package notification defines the push notification wire format shared by
+// the agent that sends notifications and the receivers that verify them:
+// request headers, event kinds, the HMAC signature and the notification JWT.
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every push notification request.
+const (
+	// HeaderNotificationToken carries PushNotificationConfig.Token.
+	HeaderNotificationToken = "X-A2A-Notification-Token"
+	// HeaderEvent carries the event kind ("status-update" or "artifact-update").
+	HeaderEvent = "X-A2A-Event"
+	// HeaderTimestamp carries the unix time (seconds) the request was signed.
+	HeaderTimestamp = "X-A2A-Timestamp"
+	// HeaderNonce carries a random, single-use value.
+	HeaderNonce = "X-A2A-Nonce"
+	// HeaderSignature carries "sha256=<hex>" HMAC of the signing string, keyed by PushNotificationConfig.Secret.
+	HeaderSignature = "X-A2A-Signature"
+	// HeaderJWT carries a JWT signed with the agent's key set.
+	HeaderJWT = "X-A2A-Notification-JWT"
+)
+
+// Event kinds reported in HeaderEvent.
+const (
+	EventStatusUpdate   = "status-update"
+	EventArtifactUpdate = "artifact-update"
+)
+
+// ValidationTokenParam is the query parameter carrying the ownership challenge.
+const ValidationTokenParam = "validationToken"
+
+// signaturePrefix identifies the HMAC algorithm in HeaderSignature.
+const signaturePrefix = "sha256="
+
+// Sign returns the HeaderSignature value for body signed at timestamp with nonce.
+// The signing string is "<timestamp>.<nonce>.<body>".
+func Sign(secret string, timestamp time.Time, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature matches body for the given secret,
+// timestamp header value and nonce, using a constant-time comparison.
+func VerifySignature(secret, signature, timestamp, nonce string, body []byte) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	expected := Sign(secret, time.Unix(unix, 0), nonce, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
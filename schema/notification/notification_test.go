@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"t-1"}`)
+	signature := Sign("secret", now, "n-1", body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	testCases := []struct {
+		description string
+		secret      string
+		signature   string
+		timestamp   string
+		nonce       string
+		body        []byte
+		expect      bool
+	}{
+		{description: "valid", secret: "secret", signature: signature, timestamp: timestamp, nonce: "n-1", body: body, expect: true},
+		{description: "wrong secret", secret: "other", signature: signature, timestamp: timestamp, nonce: "n-1", body: body},
+		{description: "wrong nonce", secret: "secret", signature: signature, timestamp: timestamp, nonce: "n-2", body: body},
+		{description: "altered body", secret: "secret", signature: signature, timestamp: timestamp, nonce: "n-1", body: []byte(`{"id":"t-2"}`)},
+		{description: "bad timestamp", secret: "secret", signature: signature, timestamp: "now", nonce: "n-1", body: body},
+		{description: "missing prefix", secret: "secret", signature: signature[len(signaturePrefix):], timestamp: timestamp, nonce: "n-1", body: body},
+	}
+	for _, testCase := range testCases {
+		if actual := VerifySignature(testCase.secret, testCase.signature, testCase.timestamp, testCase.nonce, testCase.body); actual != testCase.expect {
+			t.Errorf("%s: expected %v, got %v", testCase.description, testCase.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package schema
+
+import "fmt"
+
+// PushEvent selects the task changes delivered to a push notification config.
+type PushEvent string
+
+const (
+	// PushEventStatus delivers every status change.
+	PushEventStatus PushEvent = "status"
+	// PushEventFinal delivers only the change to a terminal state.
+	PushEventFinal PushEvent = "final"
+	// PushEventArtifact delivers artifact changes.
+	PushEventArtifact PushEvent = "artifact"
+)
+
+// ValidateEvents reports unknown entries in Events.
+func (c *PushNotificationConfig) ValidateEvents() error {
+	for _, event := range c.Events {
+		switch event {
+		case PushEventStatus, PushEventFinal, PushEventArtifact:
+		default:
+			return fmt.Errorf("unsupported push event %q", event)
+		}
+	}
+	return nil
+}
+
+// Accepts reports whether a change matches the config's event filter.
+func (c *PushNotificationConfig) Accepts(statusChanged, artifactsChanged, final bool) bool {
+	if len(c.Events) == 0 {
+		return statusChanged || artifactsChanged
+	}
+	for _, event := range c.Events {
+		switch event {
+		case PushEventStatus:
+			if statusChanged {
+				return true
+			}
+		case PushEventFinal:
+			if statusChanged && final {
+				return true
+			}
+		case PushEventArtifact:
+			if artifactsChanged {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Redacted returns a copy without the notification token, webhook secret and
+// authentication credentials, suitable for returning to callers after
+// creation. Receivers may trust any of these, so none is ever echoed.
+func (c *PushNotificationConfig) Redacted() *PushNotificationConfig {
+	out := *c
+	out.Token = nil
+	out.Secret = nil
+	if c.Authentication != nil {
+		auth := *c.Authentication
+		auth.Credentials = nil
+		out.Authentication = &auth
+	}
+	return &out
+}
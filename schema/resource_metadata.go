@@ -0,0 +1,36 @@
+package schema
+
+// ProtectedResourceMetadataPath is the well-known location of protected
+// resource metadata.
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadata is the OAuth 2.0 protected resource metadata
+// document (RFC 9728) served at ProtectedResourceMetadataPath.
+type ProtectedResourceMetadata struct {
+	// Resource is the resource identifier, an https URL without a fragment.
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers,omitempty"`
+	JWKSURI              string   `json:"jwks_uri,omitempty"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+	// BearerMethodsSupported lists how bearer tokens may be sent: "header",
+	// "body" or "query".
+	BearerMethodsSupported                []string `json:"bearer_methods_supported,omitempty"`
+	ResourceSigningAlgValuesSupported     []string `json:"resource_signing_alg_values_supported,omitempty"`
+	ResourceName                          string   `json:"resource_name,omitempty"`
+	ResourceDocumentation                 string   `json:"resource_documentation,omitempty"`
+	ResourcePolicyURI                     string   `json:"resource_policy_uri,omitempty"`
+	ResourceTOSURI                        string   `json:"resource_tos_uri,omitempty"`
+	TLSClientCertificateBoundAccessTokens bool     `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	AuthorizationDetailsTypesSupported    []string `json:"authorization_details_types_supported,omitempty"`
+	DPoPSigningAlgValuesSupported         []string `json:"dpop_signing_alg_values_supported,omitempty"`
+	DPoPBoundAccessTokensRequired         bool     `json:"dpop_bound_access_tokens_required,omitempty"`
+	// SignedMetadata is a JWT whose claims assert the metadata values; it is
+	// set when the resource server signs the document.
+	SignedMetadata string `json:"signed_metadata,omitempty"`
+
+	// Issuer, AuthorizationURI and TokenEndpoint are extensions that save
+	// clients the authorization server metadata lookup.
+	Issuer           string `json:"issuer,omitempty"`
+	AuthorizationURI string `json:"authorization_uri,omitempty"`
+	TokenEndpoint    string `json:"token_endpoint,omitempty"`
+}
@@ -0,0 +1,28 @@
+package schema
+
+// Security scheme types, following the OpenAPI 3.0 Security Scheme Object.
+const (
+	SecuritySchemeAPIKey        = "apiKey"
+	SecuritySchemeHTTP          = "http"
+	SecuritySchemeOAuth2        = "oauth2"
+	SecuritySchemeOpenIDConnect = "openIdConnect"
+	SecuritySchemeMutualTLS     = "mutualTLS"
+)
+
+// SecurityScheme describes one way of authenticating to the agent, as
+// declared in AgentCard.securitySchemes.
+type SecurityScheme struct {
+	// Type is one of the SecurityScheme* constants.
+	Type        string  `json:"type"`
+	Description *string `json:"description,omitempty"`
+	// Name and In locate an apiKey: In is "header", "query" or "cookie".
+	Name string `json:"name,omitempty"`
+	In   string `json:"in,omitempty"`
+	// Scheme is the RFC 7235 scheme of an http security scheme, e.g. "bearer" or "basic".
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	// Flows configures an oauth2 scheme.
+	Flows map[string]interface{} `json:"flows,omitempty"`
+	// OpenIDConnectURL is the discovery URL of an openIdConnect scheme.
+	OpenIDConnectURL string `json:"openIdConnectUrl,omitempty"`
+}
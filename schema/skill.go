@@ -0,0 +1,22 @@
+package schema
+
+// AgentSkill describes a distinct capability the agent can perform,
+// aligning with the spec's AgentSkill object.
+type AgentSkill struct {
+    // A unique identifier for the skill.
+    ID string `json:"id"`
+    // A human-readable name for the skill.
+    Name string `json:"name"`
+    // A detailed description of the skill.
+    Description string `json:"description,omitempty"`
+    // Keywords describing the skill's capabilities.
+    Tags []string `json:"tags,omitempty"`
+    // Example prompts or scenarios this skill can handle.
+    Examples []string `json:"examples,omitempty"`
+    // Supported input MIME types, overriding the agent defaults.
+    InputModes []string `json:"inputModes,omitempty"`
+    // Supported output MIME types, overriding the agent defaults.
+    OutputModes []string `json:"outputModes,omitempty"`
+    // Security requirements for this skill (OR of ANDs across schemes).
+    Security []map[string][]string `json:"security,omitempty"`
+}
@@ -134,8 +134,22 @@ type Task struct {
 type PushNotificationConfig struct {
 	ID  string `json:"id"`
 	URL string `json:"url"`
+	// Token is echoed in the X-A2A-Notification-Token header so the receiver can validate notifications.
+	Token *string `json:"token,omitempty"`
+	// Authentication describes how the server authenticates to the webhook.
+	Authentication *PushNotificationAuthenticationInfo `json:"authentication,omitempty"`
 	// Opaque metadata for the server when sending webhooks.
 	Secret *string `json:"secret,omitempty"`
+	// Events filters which task changes are delivered; empty means every status and artifact change.
+	Events []PushEvent `json:"events,omitempty"`
+}
+
+// PushNotificationAuthenticationInfo defines authentication details for a push notification endpoint.
+type PushNotificationAuthenticationInfo struct {
+	// Supported authentication schemes (e.g. "Bearer", "Basic").
+	Schemes []string `json:"schemes"`
+	// Optional credentials required by the push notification endpoint.
+	Credentials *string `json:"credentials,omitempty"`
 }
 
 // AgentCard describes an agent’s identity, capabilities, and endpoints.
@@ -144,9 +158,20 @@ type AgentCard struct {
     Title          *string                `json:"title,omitempty"`
     Version        *string                `json:"version,omitempty"`
     Description    *string                `json:"description,omitempty"`
+    // The preferred endpoint URL; relative URLs are resolved per request by the server.
+    URL                  string           `json:"url,omitempty"`
+    PreferredTransport   string           `json:"preferredTransport,omitempty"`
+    AdditionalInterfaces []AgentInterface `json:"additionalInterfaces,omitempty"`
     Endpoints      map[string]string      `json:"endpoints,omitempty"` // e.g. {"jsonrpc":"/v1/jsonrpc"}
     Authentication map[string]interface{} `json:"authentication,omitempty"`
     Capabilities   []string               `json:"capabilities,omitempty"`
+    Skills         []AgentSkill           `json:"skills,omitempty"`
+    // Security schemes available to authorize requests, keyed by scheme name.
+    SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+    // Security requirements for all interactions (OR of ANDs across schemes).
+    Security []map[string][]string `json:"security,omitempty"`
+    // Indicates that an extended card is available to authenticated callers.
+    SupportsAuthenticatedExtendedCard bool `json:"supportsAuthenticatedExtendedCard,omitempty"`
     capObj         *AgentCapabilities     `json:"-"`
 }
 
@@ -160,16 +185,30 @@ func (a AgentCard) MarshalJSON() ([]byte, error) {
         Title          *string                `json:"title,omitempty"`
         Version        *string                `json:"version,omitempty"`
         Description    *string                `json:"description,omitempty"`
+        URL                  string           `json:"url,omitempty"`
+        PreferredTransport   string           `json:"preferredTransport,omitempty"`
+        AdditionalInterfaces []AgentInterface `json:"additionalInterfaces,omitempty"`
         Endpoints      map[string]string      `json:"endpoints,omitempty"`
         Authentication map[string]interface{} `json:"authentication,omitempty"`
         Capabilities   interface{}            `json:"capabilities,omitempty"`
+        Skills         []AgentSkill           `json:"skills,omitempty"`
+        SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+        Security       []map[string][]string  `json:"security,omitempty"`
+        SupportsAuthenticatedExtendedCard bool `json:"supportsAuthenticatedExtendedCard,omitempty"`
     }{
         Name:           a.Name,
         Title:          a.Title,
         Version:        a.Version,
         Description:    a.Description,
+        URL:                  a.URL,
+        PreferredTransport:   a.PreferredTransport,
+        AdditionalInterfaces: a.AdditionalInterfaces,
         Endpoints:      a.Endpoints,
         Authentication: a.Authentication,
+        Skills:         a.Skills,
+        SecuritySchemes: a.SecuritySchemes,
+        Security:       a.Security,
+        SupportsAuthenticatedExtendedCard: a.SupportsAuthenticatedExtendedCard,
     }
     // Prefer object-shaped capabilities if present
     if a.capObj != nil {
@@ -188,9 +227,16 @@ func (a *AgentCard) UnmarshalJSON(b []byte) error {
         Title          *string                `json:"title,omitempty"`
         Version        *string                `json:"version,omitempty"`
         Description    *string                `json:"description,omitempty"`
+        URL                  string           `json:"url,omitempty"`
+        PreferredTransport   string           `json:"preferredTransport,omitempty"`
+        AdditionalInterfaces []AgentInterface `json:"additionalInterfaces,omitempty"`
         Endpoints      map[string]string      `json:"endpoints,omitempty"`
         Authentication map[string]interface{} `json:"authentication,omitempty"`
         Capabilities   json.RawMessage        `json:"capabilities,omitempty"`
+        Skills         []AgentSkill           `json:"skills,omitempty"`
+        SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+        Security       []map[string][]string  `json:"security,omitempty"`
+        SupportsAuthenticatedExtendedCard bool `json:"supportsAuthenticatedExtendedCard,omitempty"`
     }
     if err := json.Unmarshal(b, &aux); err != nil {
         return err
@@ -199,8 +245,15 @@ func (a *AgentCard) UnmarshalJSON(b []byte) error {
     a.Title = aux.Title
     a.Version = aux.Version
     a.Description = aux.Description
+    a.URL = aux.URL
+    a.PreferredTransport = aux.PreferredTransport
+    a.AdditionalInterfaces = aux.AdditionalInterfaces
     a.Endpoints = aux.Endpoints
     a.Authentication = aux.Authentication
+    a.Skills = aux.Skills
+    a.SecuritySchemes = aux.SecuritySchemes
+    a.Security = aux.Security
+    a.SupportsAuthenticatedExtendedCard = aux.SupportsAuthenticatedExtendedCard
     // Default empty
     a.Capabilities = nil
     if len(aux.Capabilities) == 0 || string(aux.Capabilities) == "null" {
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/viant/a2a-protocol/server/audit"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+// AuditedMethods are the methods recorded by WithAudit when no methods are given.
+var AuditedMethods = []string{
+	"message/send",
+	"message/stream",
+	"tasks/cancel",
+	"tasks/pushNotificationConfig/set",
+	"tasks/pushNotificationConfig/delete",
+	"agent/getAuthenticatedExtendedCard",
+}
+
+// WithAudit records calls to methods, AuditedMethods by default, in logger.
+// Each entry carries the caller's identity and authentication method, the
+// task, context and push config IDs involved and the outcome, including calls
+// rejected by other interceptors. onError, when not nil, is called with each
+// entry that could not be recorded.
+func WithAudit(logger *audit.Logger, onError func(entry audit.Entry, err error), methods ...string) ServerOption {
+	return func(s *Server) {
+		if len(methods) == 0 {
+			methods = AuditedMethods
+		}
+		s.auditLog, s.auditError = logger, onError
+		s.auditMethods = map[string]bool{}
+		for _, method := range methods {
+			s.auditMethods[method] = true
+		}
+	}
+}
+
+// audit is the interceptor recording audited methods once they complete.
+// Failing to record is reported to the WithAudit callback and does not fail
+// the call.
+func (s *Server) audit(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+	next(ctx, request, response)
+	if !s.auditMethods[method] {
+		return
+	}
+	entry := audit.Entry{Method: method, Outcome: audit.OutcomeOK}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal != nil {
+		owner, _, _ := ownerOf(principal)
+		entry.Tenant, entry.Subject, entry.AuthMethod = principal.Tenant, owner.Subject, principal.Method
+	}
+	var p struct {
+		ID        string `json:"id"`
+		TaskID    string `json:"taskId"`
+		ContextID string `json:"contextId"`
+		ConfigID  string `json:"configId"`
+	}
+	_ = json.Unmarshal(request.Params, &p)
+	entry.TaskID, entry.ContextID, entry.ConfigID = p.TaskID, p.ContextID, p.ConfigID
+	if method == "tasks/cancel" {
+		entry.TaskID = p.ID
+	}
+	if response.Error != nil {
+		entry.Outcome, entry.ErrorCode, entry.Error = audit.OutcomeError, response.Error.Code, response.Error.Message
+	} else {
+		var result struct {
+			ID        string `json:"id"`
+			ContextID string `json:"contextId"`
+		}
+		_ = json.Unmarshal(response.Result, &result)
+		switch method {
+		case "message/send", "message/stream":
+			entry.TaskID = result.ID
+			if result.ContextID != "" {
+				entry.ContextID = result.ContextID
+			}
+		case "tasks/pushNotificationConfig/set":
+			entry.ConfigID = result.ID
+		}
+	}
+	if err := s.auditLog.Record(entry); err != nil && s.auditError != nil {
+		s.auditError(entry, err)
+	}
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded in Entry.Outcome.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Entry is a single audit record.
+type Entry struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	Tenant  string    `json:"tenant,omitempty"`
+	Subject string    `json:"subject,omitempty"`
+	// AuthMethod is how the caller authenticated, see auth.Principal.Method.
+	AuthMethod string `json:"authMethod,omitempty"`
+	Method     string `json:"method"`
+	TaskID     string `json:"taskId,omitempty"`
+	ContextID  string `json:"contextId,omitempty"`
+	ConfigID   string `json:"configId,omitempty"`
+	Outcome    string `json:"outcome"`
+	ErrorCode  int    `json:"errorCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Prev is the hash of the preceding entry, empty for the first one.
+	Prev string `json:"prev"`
+	// Hash is the HMAC of every other field of the entry, including Prev.
+	Hash string `json:"hash"`
+}
+
+// Digest computes the chain hash of e under key, ignoring e.Hash. Without
+// the key nobody can recompute the chain after editing the log.
+func (e Entry) Digest(key []byte) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sink stores chained entries.
+type Sink interface {
+	Write(entry Entry) error
+}
+
+// Tailer is implemented by sinks that can report their last stored entry,
+// letting a Logger continue an existing chain after a restart.
+type Tailer interface {
+	Last() (Entry, bool)
+}
+
+// Logger chains entries and writes them to a Sink.
+type Logger struct {
+	sink Sink
+	key  []byte
+	mu   sync.Mutex
+	seq  int64
+	prev string
+	now  func() time.Time
+}
+
+// New returns a Logger writing to sink and keying the chain with key, which
+// must be kept secret from whoever can write the log. The chain continues
+// when sink is a Tailer.
+func New(sink Sink, key []byte) *Logger {
+	l := &Logger{sink: sink, key: key, now: time.Now}
+	if tailer, ok := sink.(Tailer); ok {
+		if last, ok := tailer.Last(); ok {
+			l.seq, l.prev = last.Seq, last.Hash
+		}
+	}
+	return l
+}
+
+// Record numbers, timestamps and chains entry, then writes it to the sink.
+// The chain only advances when the write succeeds.
+func (l *Logger) Record(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.Seq = l.seq + 1
+	if entry.Time.IsZero() {
+		entry.Time = l.now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.Prev = l.prev
+	entry.Hash = entry.Digest(l.key)
+	if err := l.sink.Write(entry); err != nil {
+		return err
+	}
+	l.seq, l.prev = entry.Seq, entry.Hash
+	return nil
+}
+
+// Head returns the sequence number and hash of the last recorded entry. Kept
+// outside the log, it lets Verify detect a truncated tail.
+func (l *Logger) Head() (int64, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq, l.prev
+}
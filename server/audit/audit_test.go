@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, WithMaxBytes(600))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sink.now = func() time.Time { now = now.Add(time.Second); return now }
+	key := []byte("audit-key")
+	logger := New(sink, key)
+	for i := 0; i < 6; i++ {
+		if err := logger.Record(Entry{Subject: "alice", Method: "message/send", TaskID: "t-1", Outcome: OutcomeOK}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	sink.Close()
+
+	// reopening continues the chain across rotated files
+	sink, err = NewFileSink(path, WithMaxBytes(600))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := New(sink, key).Record(Entry{Subject: "bob", Method: "tasks/cancel", TaskID: "t-1", Outcome: OutcomeError, ErrorCode: -32004, Error: "not found"}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	sink.Close()
+	files := Files(path)
+	if len(files) < 2 || files[len(files)-1] != path {
+		t.Fatalf("files=%v", files)
+	}
+	result, err := VerifyFiles(Expectations{Key: key, HeadSeq: 7}, files...)
+	if err != nil || result.Entries != 7 || result.First.Seq != 1 || result.Last.Seq != 7 || result.Last.Subject != "bob" {
+		t.Fatalf("result=%+v err=%v", result, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var entries []Entry
+	sink := sinkFunc(func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	key := []byte("audit-key")
+	logger := New(sink, key)
+	for _, method := range []string{"message/send", "tasks/cancel", "agent/getAuthenticatedExtendedCard"} {
+		if err := logger.Record(Entry{Subject: "alice", Method: method, Outcome: OutcomeOK}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	encode := func(entries []Entry) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		for _, entry := range entries {
+			data, _ := json.Marshal(entry)
+			buf.Write(append(data, '\n'))
+		}
+		return buf
+	}
+
+	headSeq, head := logger.Head()
+	if headSeq != 3 || head != entries[2].Hash {
+		t.Fatalf("head=%d %s", headSeq, head)
+	}
+
+	testCases := []struct {
+		description string
+		tamper      func(entries []Entry) []Entry
+		expect      Expectations
+		expectSeq   int64
+		expectError string
+	}{
+		{description: "intact", tamper: func(entries []Entry) []Entry { return entries }},
+		{description: "edited field", tamper: func(entries []Entry) []Entry {
+			entries[1].Subject = "mallory"
+			return entries
+		}, expectSeq: 2, expectError: "hash mismatch"},
+		{description: "edited and rehashed", tamper: func(entries []Entry) []Entry {
+			entries[1].Subject = "mallory"
+			entries[1].Hash = entries[1].Digest(key)
+			return entries
+		}, expectSeq: 3, expectError: "broken link"},
+		{description: "edited and rehashed without key", tamper: func(entries []Entry) []Entry {
+			entries[1].Subject = "mallory"
+			entries[1].Hash = entries[1].Digest(nil)
+			return entries
+		}, expectSeq: 2, expectError: "hash mismatch"},
+		{description: "deleted entry", tamper: func(entries []Entry) []Entry {
+			return append(entries[:1], entries[2:]...)
+		}, expectSeq: 3, expectError: "expected seq 2"},
+		{description: "reordered", tamper: func(entries []Entry) []Entry {
+			entries[1], entries[2] = entries[2], entries[1]
+			return entries
+		}, expectSeq: 3, expectError: "expected seq 2"},
+		{description: "oldest entry deleted", tamper: func(entries []Entry) []Entry { return entries[1:] }, expectSeq: 2, expectError: "does not start at seq 1"},
+		{description: "archived with anchor", tamper: func(entries []Entry) []Entry { return entries[1:] }, expect: Expectations{Anchor: entries[0].Hash}},
+		{description: "wrong anchor", tamper: func(entries []Entry) []Entry { return entries[2:] }, expect: Expectations{Anchor: entries[0].Hash}, expectSeq: 3, expectError: "does not follow the anchor"},
+		{description: "expected head", tamper: func(entries []Entry) []Entry { return entries }, expect: Expectations{HeadSeq: headSeq, Head: head}},
+		{description: "truncated below head seq", tamper: func(entries []Entry) []Entry { return entries[:2] }, expect: Expectations{HeadSeq: headSeq}, expectSeq: 3, expectError: "before expected seq 3"},
+		{description: "truncated head", tamper: func(entries []Entry) []Entry { return entries[:2] }, expect: Expectations{Head: head}, expectSeq: 3, expectError: "expected head not found"},
+	}
+	for _, testCase := range testCases {
+		tampered := testCase.tamper(append([]Entry(nil), entries...))
+		expect := testCase.expect
+		expect.Key = key
+		result, err := Verify(encode(tampered), expect)
+		if testCase.expectError == "" {
+			if err != nil || result.Entries != len(tampered) || result.Last.Hash != entries[2].Hash {
+				t.Fatalf("%s: result=%+v err=%v", testCase.description, result, err)
+			}
+			continue
+		}
+		var chainErr *ChainError
+		if !errors.As(err, &chainErr) || chainErr.Seq != testCase.expectSeq || !strings.Contains(chainErr.Reason, testCase.expectError) {
+			t.Fatalf("%s: err=%v", testCase.description, err)
+		}
+	}
+
+	// the chain does not advance on a failed write
+	failing := New(sinkFunc(func(Entry) error { return os.ErrClosed }), key)
+	if err := failing.Record(Entry{Method: "message/send"}); err == nil || failing.seq != 0 {
+		t.Fatalf("failed write: err=%v seq=%d", err, failing.seq)
+	}
+}
+
+type sinkFunc func(entry Entry) error
+
+func (f sinkFunc) Write(entry Entry) error { return f(entry) }
@@ -0,0 +1,6 @@
+// Package audit keeps a tamper-evident record of A2A operations. A Logger
+// numbers each Entry, links it to its predecessor with a SHA-256 hash chain
+// and hands it to a Sink; FileSink appends JSON lines and rotates files by
+// size. Verify and VerifyFiles recompute the chain to detect edits,
+// insertions and deletions.
+package audit
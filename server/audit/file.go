@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes is the size at which a FileSink rotates its file.
+const DefaultMaxBytes = 64 << 20
+
+// rotatedLayout names rotated files; it sorts in rotation order.
+const rotatedLayout = "20060102T150405.000000000Z"
+
+// FileOption configures a FileSink.
+type FileOption func(f *FileSink)
+
+// WithMaxBytes sets the size at which the file is rotated; 0 disables rotation.
+func WithMaxBytes(n int64) FileOption {
+	return func(f *FileSink) { f.maxBytes = n }
+}
+
+// FileSink appends entries as JSON lines to a file. When the file would grow
+// past its size limit it is renamed to "<name>.<UTC timestamp><ext>" and a new
+// file is started; the hash chain continues across files.
+type FileSink struct {
+	path     string
+	maxBytes int64
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	last     *Entry
+	now      func() time.Time
+}
+
+// NewFileSink opens or creates the log at path.
+func NewFileSink(path string, options ...FileOption) (*FileSink, error) {
+	f := &FileSink{path: path, maxBytes: DefaultMaxBytes, now: time.Now}
+	for _, option := range options {
+		option(f)
+	}
+	last, err := lastEntry(Files(path))
+	if err != nil {
+		return nil, err
+	}
+	f.last = last
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Last returns the most recent entry in the log.
+func (f *FileSink) Last() (Entry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.last == nil {
+		return Entry{}, false
+	}
+	return *f.last, true
+}
+
+// Write appends entry, rotating the file first when needed.
+func (f *FileSink) Write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(data)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(data)
+	f.size += int64(n)
+	if err != nil {
+		return err
+	}
+	f.last = &entry
+	return f.file.Sync()
+}
+
+// Close closes the current file.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	ext := filepath.Ext(f.path)
+	rotated := strings.TrimSuffix(f.path, ext) + "." + f.now().UTC().Format(rotatedLayout) + ext
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Files returns the rotated files of the log at path, oldest first, followed
+// by path itself when it exists.
+func Files(path string) []string {
+	ext := filepath.Ext(path)
+	rotated, _ := filepath.Glob(globEscape(strings.TrimSuffix(path, ext)) + ".*" + globEscape(ext))
+	var files []string
+	for _, candidate := range rotated {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(candidate, strings.TrimSuffix(path, ext)+"."), ext)
+		if _, err := time.Parse(rotatedLayout, stamp); err == nil {
+			files = append(files, candidate)
+		}
+	}
+	sort.Strings(files)
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
+
+// lastEntry reads the final entry of the newest non-empty file.
+func lastEntry(files []string) (*Entry, error) {
+	for i := len(files) - 1; i >= 0; i-- {
+		file, err := os.Open(files[i])
+		if err != nil {
+			return nil, err
+		}
+		var last *Entry
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), maxLine)
+		for line := 1; scanner.Scan(); line++ {
+			var entry Entry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("audit: %s:%d: %w", files[i], line, err)
+			}
+			last = &entry
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			return last, nil
+		}
+	}
+	return nil, nil
+}
+
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(s)
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxLine bounds the size of a single JSON line.
+const maxLine = 1 << 20
+
+// Expectations describe the chain Verify accepts.
+type Expectations struct {
+	// Key is the Logger key the chain was written with.
+	Key []byte
+	// Anchor is the hash of the entry preceding the first one in the log,
+	// recorded before older files were archived. Without it the log must
+	// start at seq 1.
+	Anchor string
+	// HeadSeq, when set, is the sequence number the log must reach.
+	HeadSeq int64
+	// Head, when set, is a hash, e.g. from Logger.Head, the log must contain.
+	Head string
+}
+
+// Result summarizes a verified chain.
+type Result struct {
+	Entries int   `json:"entries"`
+	First   Entry `json:"first"`
+	Last    Entry `json:"last"`
+}
+
+// ChainError describes the first entry breaking the chain.
+type ChainError struct {
+	File   string
+	Line   int
+	Seq    int64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("audit: line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+	}
+	return fmt.Sprintf("audit: %s:%d (seq %d): %s", e.File, e.Line, e.Seq, e.Reason)
+}
+
+// Verify checks the JSON lines read from r form an intact chain meeting expect.
+func Verify(r io.Reader, expect Expectations) (Result, error) {
+	var result Result
+	found := false
+	if err := verify(r, "", expect, &result, &found); err != nil {
+		return result, err
+	}
+	return result, checkHead(expect, result, found)
+}
+
+// VerifyFiles checks files, in order, form a single intact chain meeting
+// expect. Use Files to list a rotated log.
+func VerifyFiles(expect Expectations, files ...string) (Result, error) {
+	var result Result
+	found := false
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return result, err
+		}
+		err = verify(file, name, expect, &result, &found)
+		file.Close()
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, checkHead(expect, result, found)
+}
+
+// checkHead reports a log that ends before the expected head, e.g. because
+// its tail was truncated.
+func checkHead(expect Expectations, result Result, found bool) error {
+	if expect.HeadSeq > 0 && result.Last.Seq < expect.HeadSeq {
+		return &ChainError{Seq: result.Last.Seq + 1, Reason: fmt.Sprintf("log ends before expected seq %d", expect.HeadSeq)}
+	}
+	if expect.Head != "" && !found {
+		return &ChainError{Seq: result.Last.Seq + 1, Reason: "expected head not found"}
+	}
+	return nil
+}
+
+func verify(r io.Reader, name string, expect Expectations, result *Result, found *bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return &ChainError{File: name, Line: line, Seq: result.Last.Seq + 1, Reason: err.Error()}
+		}
+		fail := func(reason string) error {
+			return &ChainError{File: name, Line: line, Seq: entry.Seq, Reason: reason}
+		}
+		if !hmac.Equal([]byte(entry.Hash), []byte(entry.Digest(expect.Key))) {
+			return fail("hash mismatch")
+		}
+		switch {
+		case result.Entries == 0:
+			if expect.Anchor != "" {
+				if entry.Prev != expect.Anchor {
+					return fail("first entry does not follow the anchor")
+				}
+			} else if entry.Seq != 1 || entry.Prev != "" {
+				return fail("chain does not start at seq 1")
+			}
+			result.First = entry
+		case entry.Seq != result.Last.Seq+1:
+			return fail(fmt.Sprintf("expected seq %d", result.Last.Seq+1))
+		case entry.Prev != result.Last.Hash:
+			return fail("broken link to previous entry")
+		}
+		result.Last = entry
+		result.Entries++
+		if entry.Hash == expect.Head {
+			*found = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &ChainError{File: name, Seq: result.Last.Seq + 1, Reason: err.Error()}
+	}
+	return nil
+}
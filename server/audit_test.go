@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/audit"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+type auditSink []audit.Entry
+
+func (s *auditSink) Write(entry audit.Entry) error {
+	*s = append(*s, entry)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	sink := &auditSink{}
+	srv := New(schema.AgentCard{Name: "test"}, WithAudit(audit.New(sink, []byte("audit-key")), nil))
+	mux := http.NewServeMux()
+	srv.RegisterJSONRPC(mux, "/rpc")
+	// callers are identified by X-User or, for clients without a subject,
+	// X-Client, standing in for the auth middleware
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user := r.Header.Get("X-User"); user != "" {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Subject: user, Tenant: "acme", Method: auth.MethodJWT}))
+		} else if client := r.Header.Get("X-Client"); client != "" {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Tenant: "acme", Claims: map[string]interface{}{"client_id": client}, Method: auth.MethodAPIKey}))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	call := func(header, user, method string, params interface{}) rpcResp {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/rpc", bytes.NewReader(body))
+		req.Header.Set(header, user)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer resp.Body.Close()
+		var out rpcResp
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+	contextID := "ctx-1"
+	var task schema.Task
+	rpc := call("X-User", "alice", "message/send", map[string]interface{}{"contextId": contextID, "messages": []map[string]interface{}{{"role": "user", "parts": []map[string]interface{}{{"type": "text", "text": "hi"}}}}})
+	if rpc.Error != nil || json.Unmarshal(rpc.Result, &task) != nil {
+		t.Fatalf("send: %+v", rpc.Error)
+	}
+	call("X-User", "alice", "tasks/get", map[string]string{"id": task.ID})
+	call("X-User", "bob", "tasks/cancel", map[string]string{"id": task.ID})
+	call("X-Client", "billing-svc", "tasks/cancel", map[string]string{"id": task.ID})
+
+	testCases := []struct {
+		description string
+		expect      audit.Entry
+	}{
+		{description: "task creation", expect: audit.Entry{Seq: 1, Tenant: "acme", Subject: "alice", AuthMethod: auth.MethodJWT, Method: "message/send", TaskID: task.ID, ContextID: contextID, Outcome: audit.OutcomeOK}},
+		{description: "rejected cancellation", expect: audit.Entry{Seq: 2, Tenant: "acme", Subject: "bob", AuthMethod: auth.MethodJWT, Method: "tasks/cancel", TaskID: task.ID, Outcome: audit.OutcomeError, ErrorCode: -32004, Error: "not found"}},
+		{description: "client identified by client_id", expect: audit.Entry{Seq: 3, Tenant: "acme", Subject: "billing-svc", AuthMethod: auth.MethodAPIKey, Method: "tasks/cancel", TaskID: task.ID, Outcome: audit.OutcomeError, ErrorCode: -32004, Error: "not found"}},
+	}
+	if len(*sink) != len(testCases) {
+		t.Fatalf("entries=%+v", *sink)
+	}
+	prev := ""
+	for i, testCase := range testCases {
+		actual := (*sink)[i]
+		if actual.Time.IsZero() || actual.Prev != prev || actual.Hash != actual.Digest([]byte("audit-key")) {
+			t.Fatalf("%s: chain: %+v", testCase.description, actual)
+		}
+		prev = actual.Hash
+		actual.Time, actual.Prev, actual.Hash = testCase.expect.Time, "", ""
+		if actual != testCase.expect {
+			t.Fatalf("%s: got %+v, want %+v", testCase.description, actual, testCase.expect)
+		}
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(audit.Entry) error { return errors.New("disk full") }
+
+func TestAudit_ErrorCallback(t *testing.T) {
+	var failed []string
+	onError := func(entry audit.Entry, err error) { failed = append(failed, entry.Method+": "+err.Error()) }
+	srv := New(schema.AgentCard{Name: "test"}, WithAudit(audit.New(failingSink{}, []byte("audit-key")), onError))
+	response := &jsonrpc.Response{}
+	srv.audit(context.Background(), "tasks/cancel", &jsonrpc.Request{Params: []byte(`{"id":"t-1"}`)}, response, func(context.Context, *jsonrpc.Request, *jsonrpc.Response) {})
+	if len(failed) != 1 || failed[0] != "tasks/cancel: disk full" {
+		t.Fatalf("failed=%v", failed)
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeyHeader is where APIKeyAuthenticator looks for a key when
+// neither it nor the security scheme names a location.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+var (
+	// ErrInvalidCredentials is returned for an unknown API key or a wrong password.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrCredentialsExpired is returned for an API key past its expiry.
+	ErrCredentialsExpired = errors.New("credentials expired")
+)
+
+// APIKey is a stored API key. Only the SHA-256 hash of the key is kept.
+type APIKey struct {
+	ID string `json:"id"`
+	// Hash is the hex SHA-256 of the key, see HashAPIKey.
+	Hash    string   `json:"hash"`
+	Subject string   `json:"subject,omitempty"`
+	Tenant  string   `json:"tenant,omitempty"`
+	Scopes  []string `json:"scopes,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	// ExpiresAt, when set, is when the key stops being accepted.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// HashAPIKey returns the hex SHA-256 of key as stored in APIKey.Hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore resolves API keys by hash.
+type KeyStore struct {
+	mu     sync.RWMutex
+	byHash map[string]*APIKey
+	now    func() time.Time
+}
+
+// NewKeyStore returns a store holding keys.
+func NewKeyStore(keys ...*APIKey) *KeyStore {
+	s := &KeyStore{byHash: map[string]*APIKey{}, now: time.Now}
+	for _, key := range keys {
+		s.Add(key)
+	}
+	return s
+}
+
+// Add stores or replaces key.
+func (s *KeyStore) Add(key *APIKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byHash[key.Hash] = key
+}
+
+// Revoke removes the key with the given ID.
+func (s *KeyStore) Revoke(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, key := range s.byHash {
+		if key.ID == id {
+			delete(s.byHash, hash)
+		}
+	}
+}
+
+// Lookup returns the principal of key; use it as APIKeyAuthenticator.Lookup.
+func (s *KeyStore) Lookup(_ context.Context, key string) (*Principal, error) {
+	s.mu.RLock()
+	stored, ok := s.byHash[HashAPIKey(key)]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	if !stored.ExpiresAt.IsZero() && !s.now().Before(stored.ExpiresAt) {
+		return nil, ErrCredentialsExpired
+	}
+	subject := stored.Subject
+	if subject == "" {
+		subject = stored.ID
+	}
+	return &Principal{
+		Subject: subject,
+		Tenant:  stored.Tenant,
+		Scopes:  append([]string(nil), stored.Scopes...),
+		Roles:   append([]string(nil), stored.Roles...),
+		Claims:  map[string]interface{}{"key_id": stored.ID},
+	}, nil
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/viant/a2a-protocol/schema"
+)
+
+// Authenticator kinds, used as keys of Service.Authenticators. The kind of a
+// declared scheme is given by SchemeKind.
+const (
+	KindBearer    = "bearer"
+	KindBasic     = "basic"
+	KindAPIKey    = "apiKey"
+	KindMutualTLS = "mutualTLS"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// no credential for the scheme.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator verifies the credential a request presents for a declared
+// security scheme. It returns ErrNoCredentials when none is present.
+type Authenticator interface {
+	Authenticate(r *http.Request, scheme schema.SecurityScheme) (*Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc func(r *http.Request, scheme schema.SecurityScheme) (*Principal, error)
+
+// Authenticate calls f.
+func (f AuthenticatorFunc) Authenticate(r *http.Request, scheme schema.SecurityScheme) (*Principal, error) {
+	return f(r, scheme)
+}
+
+// SchemeKind maps a declared security scheme to the authenticator kind that
+// verifies it: http bearer, oauth2 and openIdConnect all present bearer tokens.
+func SchemeKind(scheme schema.SecurityScheme) string {
+	switch scheme.Type {
+	case schema.SecuritySchemeHTTP:
+		if strings.EqualFold(scheme.Scheme, "basic") {
+			return KindBasic
+		}
+		return KindBearer
+	case schema.SecuritySchemeOAuth2, schema.SecuritySchemeOpenIDConnect:
+		return KindBearer
+	case schema.SecuritySchemeAPIKey:
+		return KindAPIKey
+	case schema.SecuritySchemeMutualTLS:
+		return KindMutualTLS
+	}
+	return ""
+}
+
+// errNoValidator rejects bearer tokens when no validator is configured.
+var errNoValidator = errors.New("bearer: no token validator configured")
+
+// BearerAuthenticator verifies Authorization: Bearer tokens with Validator.
+// Without one every token is rejected unless AcceptAny is set.
+type BearerAuthenticator struct {
+	Validator TokenValidator
+	// AcceptAny accepts any token when Validator is nil. Meant for demos only.
+	AcceptAny bool
+}
+
+// Authenticate implements Authenticator.
+func (a *BearerAuthenticator) Authenticate(r *http.Request, _ schema.SecurityScheme) (*Principal, error) {
+	authz := r.Header.Get("Authorization")
+	if !hasBearer(authz) {
+		return nil, ErrNoCredentials
+	}
+	if a.Validator == nil {
+		if !a.AcceptAny {
+			return nil, errNoValidator
+		}
+		return &Principal{Method: MethodBearer}, nil
+	}
+	raw := strings.TrimSpace(strings.TrimSpace(authz)[len("bearer "):])
+	claims, err := a.Validator.Validate(r.Context(), raw)
+	if err != nil {
+		return nil, err
+	}
+	return PrincipalFromClaims(claims, MethodJWT), nil
+}
+
+// errNoLookup rejects API keys when no Lookup is configured.
+var errNoLookup = errors.New("apiKey: no key lookup configured")
+
+// APIKeyAuthenticator reads the key from the header, query parameter or
+// cookie named by the scheme and resolves it with Lookup, e.g. KeyStore.Lookup.
+// Without Lookup every key is rejected.
+type APIKeyAuthenticator struct {
+	Lookup func(ctx context.Context, key string) (*Principal, error)
+	// Name and In locate the key when the scheme does not; DefaultAPIKeyHeader when empty.
+	Name string
+	In   string
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request, scheme schema.SecurityScheme) (*Principal, error) {
+	if scheme.Name == "" {
+		scheme.Name, scheme.In = a.Name, a.In
+		if scheme.Name == "" {
+			scheme.Name, scheme.In = DefaultAPIKeyHeader, "header"
+		}
+	}
+	key := APIKeyFromRequest(r, scheme)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	if a.Lookup == nil {
+		return nil, errNoLookup
+	}
+	principal, err := a.Lookup(r.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+	principal.Method = MethodAPIKey
+	return principal, nil
+}
+
+// APIKeyFromRequest returns the API key located by scheme.In and scheme.Name.
+func APIKeyFromRequest(r *http.Request, scheme schema.SecurityScheme) string {
+	switch strings.ToLower(scheme.In) {
+	case "query":
+		return r.URL.Query().Get(scheme.Name)
+	case "cookie":
+		if cookie, err := r.Cookie(scheme.Name); err == nil {
+			return cookie.Value
+		}
+		return ""
+	}
+	return r.Header.Get(scheme.Name)
+}
+
+// BasicAuthenticator verifies Authorization: Basic credentials with Verify,
+// e.g. CredentialsFile.Verify.
+type BasicAuthenticator struct {
+	Verify func(ctx context.Context, username, password string) (*Principal, error)
+}
+
+// Authenticate implements Authenticator.
+func (a *BasicAuthenticator) Authenticate(r *http.Request, _ schema.SecurityScheme) (*Principal, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	principal, err := a.Verify(r.Context(), username, password)
+	if err != nil {
+		return nil, err
+	}
+	principal.Method = MethodBasic
+	return principal, nil
+}
+
+// MutualTLSAuthenticator accepts a client certificate verified by the TLS
+// handshake (see TLSOptions). Map derives the principal, PrincipalFromCertificate
+// by default.
+type MutualTLSAuthenticator struct {
+	Map func(cert *x509.Certificate) (*Principal, error)
+	// TrustDomains, when set, admits only certificates whose SPIFFE ID belongs
+	// to one of these trust domains.
+	TrustDomains []string
+}
+
+// Authenticate implements Authenticator.
+func (a *MutualTLSAuthenticator) Authenticate(r *http.Request, _ schema.SecurityScheme) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	if len(a.TrustDomains) > 0 && !contains(a.TrustDomains, spiffeTrustDomain(SPIFFEID(cert))) {
+		return nil, fmt.Errorf("client certificate: %w", errUntrustedDomain)
+	}
+	if a.Map == nil {
+		return PrincipalFromCertificate(cert), nil
+	}
+	principal, err := a.Map(cert)
+	if err != nil {
+		return nil, fmt.Errorf("client certificate: %w", err)
+	}
+	principal.Method = MethodMutualTLS
+	return principal, nil
+}
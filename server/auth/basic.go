@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	passwordHashPrefix = "pbkdf2-sha256"
+	// DefaultPasswordIterations is the PBKDF2 iteration count used by HashPassword.
+	DefaultPasswordIterations = 210000
+)
+
+// HashPassword returns a salted PBKDF2-HMAC-SHA256 hash of password in the
+// form pbkdf2-sha256$<iterations>$<salt>$<hash>, as stored in a credentials file.
+func HashPassword(password string, iterations int) (string, error) {
+	if iterations <= 0 {
+		iterations = DefaultPasswordIterations
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2SHA256([]byte(password), salt, iterations, sha256.Size)
+	encoding := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", passwordHashPrefix, iterations, encoding.EncodeToString(salt), encoding.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches a HashPassword hash.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordHashPrefix {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	encoding := base64.RawStdEncoding
+	salt, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expected, err := encoding.DecodeString(parts[3])
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	actual := pbkdf2SHA256([]byte(password), salt, iterations, len(expected))
+	return subtle.ConstantTimeCompare(actual, expected) == 1
+}
+
+// pbkdf2SHA256 implements RFC 8018 PBKDF2 with HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var out []byte
+	for block := uint32(1); len(out) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		_ = binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
+
+// basicUser is one entry of a credentials file.
+type basicUser struct {
+	hash   string
+	scopes []string
+	roles  []string
+}
+
+// CredentialsFile holds Basic credentials loaded from a local file and
+// reloaded when the file changes. Each non-empty line not starting with "#" is
+//
+//	username:hash[:scope,scope[:role,role]]
+//
+// where hash comes from HashPassword.
+type CredentialsFile struct {
+	Path string
+	// ReloadInterval bounds how often the file is checked for changes; 5s when zero.
+	ReloadInterval time.Duration
+
+	mu        sync.RWMutex
+	users     map[string]*basicUser
+	dummyHash string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewCredentialsFile loads path.
+func NewCredentialsFile(path string) (*CredentialsFile, error) {
+	f := &CredentialsFile{Path: path}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Reload re-reads the file, keeping the previous credentials on error.
+func (f *CredentialsFile) Reload() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+	users, err := parseCredentials(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Path, err)
+	}
+	f.mu.Lock()
+	f.users, f.dummyHash, f.modTime, f.checkedAt = users, dummyHash(users), info.ModTime(), time.Now()
+	f.mu.Unlock()
+	return nil
+}
+
+// Verify checks username and password; use it as BasicAuthenticator.Verify.
+// Unknown usernames are checked against a dummy hash, so they take as long to
+// reject as wrong passwords and cannot be told apart by timing.
+func (f *CredentialsFile) Verify(_ context.Context, username, password string) (*Principal, error) {
+	f.reloadIfChanged()
+	f.mu.RLock()
+	user, ok := f.users[username]
+	dummy := f.dummyHash
+	f.mu.RUnlock()
+	if !ok {
+		CheckPassword(dummy, password)
+		return nil, ErrInvalidCredentials
+	}
+	if !CheckPassword(user.hash, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{
+		Subject: username,
+		Scopes:  append([]string(nil), user.scopes...),
+		Roles:   append([]string(nil), user.roles...),
+	}, nil
+}
+
+func (f *CredentialsFile) reloadIfChanged() {
+	interval := f.ReloadInterval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	f.mu.RLock()
+	due := time.Since(f.checkedAt) >= interval
+	modTime := f.modTime
+	f.mu.RUnlock()
+	if !due {
+		return
+	}
+	f.mu.Lock()
+	f.checkedAt = time.Now()
+	f.mu.Unlock()
+	if info, err := os.Stat(f.Path); err == nil && !info.ModTime().Equal(modTime) {
+		_ = f.Reload()
+	}
+}
+
+func parseCredentials(data []byte) (map[string]*basicUser, error) {
+	users := map[string]*basicUser{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, ":")
+		if len(fields) < 2 || fields[0] == "" || !strings.HasPrefix(fields[1], passwordHashPrefix+"$") {
+			return nil, fmt.Errorf("line %d: expected username:hash", line)
+		}
+		user := &basicUser{hash: fields[1]}
+		if len(fields) > 2 {
+			user.scopes = splitList(fields[2])
+		}
+		if len(fields) > 3 {
+			user.roles = splitList(fields[3])
+		}
+		users[fields[0]] = user
+	}
+	return users, scanner.Err()
+}
+
+// dummyHash returns a hash no password matches, costing as many iterations as
+// the slowest hash in users, or DefaultPasswordIterations without users.
+func dummyHash(users map[string]*basicUser) string {
+	iterations := 0
+	for _, user := range users {
+		if parts := strings.Split(user.hash, "$"); len(parts) == 4 {
+			if n, err := strconv.Atoi(parts[1]); err == nil && n > iterations {
+				iterations = n
+			}
+		}
+	}
+	if iterations == 0 {
+		iterations = DefaultPasswordIterations
+	}
+	encoding := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", passwordHashPrefix, iterations, encoding.EncodeToString(make([]byte, 16)), encoding.EncodeToString(make([]byte, sha256.Size)))
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
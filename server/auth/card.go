@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/viant/a2a-protocol/schema"
+)
+
+// authenticator returns the authenticator registered for kind. Bearer falls
+// back to Validator, or to accepting any token with AcceptAnyBearer, and
+// mutual TLS to the built-in authenticator; API key and basic must be
+// registered. Without one the scheme fails closed.
+func (s *Service) authenticator(kind string) Authenticator {
+	if a, ok := s.Authenticators[kind]; ok {
+		return a
+	}
+	switch kind {
+	case KindBearer:
+		if s.Validator == nil && !s.AcceptAnyBearer {
+			return nil
+		}
+		return &BearerAuthenticator{Validator: s.Validator, AcceptAny: s.AcceptAnyBearer}
+	case KindMutualTLS:
+		return &MutualTLSAuthenticator{}
+	}
+	return nil
+}
+
+// schemeError reports why a declared scheme rejected the request.
+type schemeError struct {
+	name   string
+	kind   string
+	err    error
+	scopes []string // set when the scheme authenticated but lacked scopes
+}
+
+func (e *schemeError) Error() string {
+	if e.scopes != nil {
+		return fmt.Sprintf("%s: insufficient scope", e.name)
+	}
+	return fmt.Sprintf("%s: %v", e.name, e.err)
+}
+
+// authenticateCard enforces card.Security: the request passes when it
+// satisfies every scheme of at least one requirement set.
+func (s *Service) authenticateCard(r *http.Request, card schema.AgentCard) (*http.Request, *denial) {
+	if len(card.Security) == 0 {
+		return r, nil
+	}
+	if !hasBearer(r.Header.Get("Authorization")) {
+		if token := s.acquireToken(r); token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	var failures []*schemeError
+	noCredentials := true
+	for _, requirement := range card.Security {
+		principal, failure := s.satisfy(r, card.SecuritySchemes, requirement)
+		if failure == nil {
+			return withPrincipal(r, principal), nil
+		}
+		failures = append(failures, failure)
+		noCredentials = noCredentials && errors.Is(failure.err, ErrNoCredentials)
+	}
+	for _, failure := range failures {
+		if failure.scopes != nil {
+			return r, insufficientScope(failure.scopes)
+		}
+	}
+	return r, &denial{
+		status:        http.StatusUnauthorized,
+		challenges:    s.challenges(r, card, failures),
+		body:          `{"error":"Unauthorized: credentials required"}`,
+		reason:        failures[0].Error(),
+		noCredentials: noCredentials,
+	}
+}
+
+// satisfy authenticates every scheme of one requirement set, merging the
+// resulting principals.
+func (s *Service) satisfy(r *http.Request, schemes map[string]schema.SecurityScheme, requirement map[string][]string) (*Principal, *schemeError) {
+	var principal *Principal
+	for _, name := range sortedKeys(requirement) {
+		scheme, ok := schemes[name]
+		if !ok {
+			return nil, &schemeError{name: name, err: errors.New("undeclared security scheme")}
+		}
+		kind := SchemeKind(scheme)
+		authenticator := s.authenticator(kind)
+		if authenticator == nil {
+			return nil, &schemeError{name: name, kind: kind, err: errors.New("no authenticator registered")}
+		}
+		p, err := authenticator.Authenticate(r, scheme)
+		if err != nil {
+			return nil, &schemeError{name: name, kind: kind, err: err}
+		}
+		if scopes := requirement[name]; !(Requirement{Scopes: scopes}).Satisfied(p) {
+			return nil, &schemeError{name: name, kind: kind, scopes: scopes}
+		}
+		if principal == nil {
+			principal = &Principal{Method: p.Method}
+		}
+		mergePrincipal(principal, p)
+	}
+	return principal, nil
+}
+
+// mergePrincipal adds the identity, grants and claims of p to merged without
+// sharing p's slices or claims. Fields merged already set win.
+func mergePrincipal(merged, p *Principal) {
+	if merged.Subject == "" {
+		merged.Subject = p.Subject
+	}
+	if merged.Tenant == "" {
+		merged.Tenant = p.Tenant
+	}
+	merged.Scopes = append(merged.Scopes, p.Scopes...)
+	merged.Roles = append(merged.Roles, p.Roles...)
+	merged.Audience = append(merged.Audience, p.Audience...)
+	for key, value := range p.Claims {
+		if merged.Claims == nil {
+			merged.Claims = map[string]interface{}{}
+		}
+		if _, ok := merged.Claims[key]; !ok {
+			merged.Claims[key] = value
+		}
+	}
+}
+
+// challenges builds one WWW-Authenticate value per declared way of
+// authenticating. Bearer-based schemes share a single challenge carrying the
+// union of their required scopes; mutual TLS has none.
+func (s *Service) challenges(r *http.Request, card schema.AgentCard, failures []*schemeError) []string {
+	var result []string
+	var bearer bool
+	var bearerScopes []string
+	seen := map[string]bool{}
+	for _, requirement := range card.Security {
+		for _, name := range sortedKeys(requirement) {
+			scheme, ok := card.SecuritySchemes[name]
+			if !ok {
+				continue
+			}
+			switch SchemeKind(scheme) {
+			case KindBearer:
+				bearer = true
+				for _, scope := range requirement[name] {
+					if !contains(bearerScopes, scope) {
+						bearerScopes = append(bearerScopes, scope)
+					}
+				}
+				continue
+			case KindBasic:
+				name = KindBasic
+			case KindMutualTLS:
+				continue
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if SchemeKind(scheme) == KindBasic {
+				result = append(result, fmt.Sprintf(`Basic realm="%s", charset="UTF-8"`, quoteEscape(card.Name)))
+				continue
+			}
+			in := scheme.In
+			if in == "" {
+				in = "header"
+			}
+			result = append(result, fmt.Sprintf(`ApiKey realm="%s", in="%s", name="%s"`, quoteEscape(card.Name), in, scheme.Name))
+		}
+	}
+	if !bearer {
+		return result
+	}
+	challenge := `Bearer resource_metadata="` + s.resourceMetadataURL(r) + `"`
+	if _, metadata := s.metadataFor(r.URL.Path); len(bearerScopes) == 0 && metadata != nil {
+		bearerScopes = metadata.ScopesSupported
+	}
+	if len(bearerScopes) > 0 {
+		challenge += `, scope="` + strings.Join(bearerScopes, " ") + `"`
+	}
+	for _, failure := range failures {
+		if failure.kind == KindBearer && failure.err != nil && !errors.Is(failure.err, ErrNoCredentials) {
+			// RFC 6750 section 3.1
+			s.logInvalidToken(r, failure.err)
+			challenge += invalidTokenChallenge
+			break
+		}
+	}
+	return append([]string{challenge}, result...)
+}
+
+func sortedKeys(requirement map[string][]string) []string {
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
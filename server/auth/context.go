@@ -1,12 +1,20 @@
 package auth
 
-import "context"
+import (
+	"context"
+
+	"github.com/viant/a2a-protocol/jwt"
+)
 
 type tokenKeyType string
 
-const tokenKey tokenKeyType = "a2a-auth-token"
+const (
+	tokenKey  tokenKeyType = "a2a-auth-token"
+	claimsKey tokenKeyType = "a2a-auth-claims"
+)
 
-// Token represents a bearer token (raw header value).
+// Token represents a bearer token (raw header value). Prefer Principal, which
+// carries the identity established by the authenticator.
 type Token struct {
 	// Raw is the full Authorization header value, e.g. "Bearer <token>".
 	Raw string
@@ -26,3 +34,14 @@ func FromContext(ctx context.Context) (*Token, bool) {
 	t, ok := v.(*Token)
 	return t, ok
 }
+
+// WithClaims attaches validated token claims to context.
+func WithClaims(ctx context.Context, claims jwt.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext extracts validated token claims if present.
+func ClaimsFromContext(ctx context.Context) (jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(jwt.Claims)
+	return claims, ok
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+)
+
+// Introspection cache defaults.
+const (
+	DefaultIntrospectionTTL         = time.Minute
+	DefaultIntrospectionNegativeTTL = 10 * time.Second
+	DefaultIntrospectionMaxEntries  = 10000
+	// DefaultIntrospectionMaxNegativeEntries bounds cached inactive tokens.
+	DefaultIntrospectionMaxNegativeEntries = 1000
+)
+
+// IntrospectionValidator validates opaque bearer tokens with an RFC 7662
+// introspection endpoint. Use it as Service.Validator, or register it under
+// KindBearer in Service.Authenticators to report MethodIntrospection.
+//
+// Active and inactive results are cached, keyed by a hash of the token:
+// active ones for TTL but never past the token's exp, inactive ones for
+// NegativeTTL. Inactive results are kept apart, so a flood of bogus tokens
+// cannot evict active ones. Endpoint failures are not cached.
+type IntrospectionValidator struct {
+	Endpoint string
+	// ClientID and ClientSecret authenticate the resource server with HTTP Basic.
+	ClientID     string
+	ClientSecret string
+	// Issuer and Audience, when set, must match the iss and aud of the response.
+	Issuer   string
+	Audience string
+	// TTL, NegativeTTL, MaxEntries and MaxNegativeEntries bound the cache;
+	// see the Default constants.
+	TTL                time.Duration
+	NegativeTTL        time.Duration
+	MaxEntries         int
+	MaxNegativeEntries int
+	// HTTP defaults to http.DefaultClient.
+	HTTP *http.Client
+
+	mu       sync.Mutex
+	cache    map[string]introspectionEntry
+	negative map[string]introspectionEntry
+	now      func() time.Time
+}
+
+type introspectionEntry struct {
+	claims  jwt.Claims
+	expires time.Time
+}
+
+// Validate implements TokenValidator.
+func (v *IntrospectionValidator) Validate(ctx context.Context, token string) (jwt.Claims, error) {
+	key := sha256.Sum256([]byte(token))
+	cacheKey := hex.EncodeToString(key[:])
+	if claims, ok := v.cached(cacheKey); ok {
+		if claims == nil {
+			return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+		}
+		return claims, nil
+	}
+	claims, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	now := v.clock()
+	if active, _ := claims["active"].(bool); !active {
+		v.store(cacheKey, nil, now.Add(v.negativeTTL()))
+		return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	}
+	if err = claims.Validate(jwt.Expectations{Issuer: v.Issuer, Audience: v.Audience, Now: now}); err != nil {
+		v.store(cacheKey, nil, now.Add(v.negativeTTL()))
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	expires := now.Add(v.ttl())
+	if exp, ok, _ := claims.NumericDate("exp"); ok && exp.Before(expires) {
+		expires = exp
+	}
+	v.store(cacheKey, claims, expires)
+	return claims, nil
+}
+
+// Authenticate implements Authenticator for bearer schemes.
+func (v *IntrospectionValidator) Authenticate(r *http.Request, _ schema.SecurityScheme) (*Principal, error) {
+	authz := r.Header.Get("Authorization")
+	if !hasBearer(authz) {
+		return nil, ErrNoCredentials
+	}
+	claims, err := v.Validate(r.Context(), strings.TrimSpace(strings.TrimSpace(authz)[len("bearer "):]))
+	if err != nil {
+		return nil, err
+	}
+	return PrincipalFromClaims(claims, MethodIntrospection), nil
+}
+
+// introspect posts token to the endpoint and decodes the response.
+func (v *IntrospectionValidator) introspect(ctx context.Context, token string) (jwt.Claims, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if v.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(v.ClientID), url.QueryEscape(v.ClientSecret))
+	}
+	httpClient := v.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection: status %d", resp.StatusCode)
+	}
+	claims := jwt.Claims{}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("introspection: decode: %w", err)
+	}
+	return claims, nil
+}
+
+// cached returns the cached claims for key; nil claims mark an inactive token.
+func (v *IntrospectionValidator) cached(key string) (jwt.Claims, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.clock()
+	for _, entries := range []map[string]introspectionEntry{v.cache, v.negative} {
+		entry, ok := entries[key]
+		if !ok {
+			continue
+		}
+		if !now.Before(entry.expires) {
+			delete(entries, key)
+			return nil, false
+		}
+		return entry.claims, true
+	}
+	return nil, false
+}
+
+// store caches claims for key, or an inactive token when claims is nil.
+func (v *IntrospectionValidator) store(key string, claims jwt.Claims, expires time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cache == nil {
+		v.cache, v.negative = map[string]introspectionEntry{}, map[string]introspectionEntry{}
+	}
+	entries, limit := v.cache, v.MaxEntries
+	if limit <= 0 {
+		limit = DefaultIntrospectionMaxEntries
+	}
+	if claims == nil {
+		entries, limit = v.negative, v.MaxNegativeEntries
+		if limit <= 0 {
+			limit = DefaultIntrospectionMaxNegativeEntries
+		}
+	}
+	if len(entries) >= limit {
+		evict(entries, limit, v.clock())
+	}
+	entries[key] = introspectionEntry{claims: claims, expires: expires}
+}
+
+// evict drops expired entries and then those expiring soonest until entries
+// has room for one more below limit.
+func evict(entries map[string]introspectionEntry, limit int, now time.Time) {
+	for k, entry := range entries {
+		if !now.Before(entry.expires) {
+			delete(entries, k)
+		}
+	}
+	for len(entries) >= limit {
+		var oldest string
+		for k, entry := range entries {
+			if oldest == "" || entry.expires.Before(entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(entries, oldest)
+	}
+}
+
+func (v *IntrospectionValidator) clock() time.Time {
+	if v.now != nil {
+		return v.now()
+	}
+	return time.Now()
+}
+
+func (v *IntrospectionValidator) ttl() time.Duration {
+	if v.TTL > 0 {
+		return v.TTL
+	}
+	return DefaultIntrospectionTTL
+}
+
+func (v *IntrospectionValidator) negativeTTL() time.Duration {
+	if v.NegativeTTL > 0 {
+		return v.NegativeTTL
+	}
+	return DefaultIntrospectionNegativeTTL
+}
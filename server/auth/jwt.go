@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+)
+
+// ErrInvalidToken is returned when a bearer token fails validation.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenValidator validates a bearer token and returns its claims.
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (jwt.Claims, error)
+}
+
+// JWTValidator validates bearer JWTs signed with HS256, RS256, ES256 or EdDSA.
+type JWTValidator struct {
+	// Keys resolves the verification key for a token; use StaticKeys or
+	// jwt.NewRemoteKeySet(jwksURL).KeyFunc().
+	Keys jwt.KeyFunc
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// ClockSkew tolerates clock differences when checking exp, nbf and iat.
+	ClockSkew time.Duration
+	// Algorithms restricts accepted algorithms; all supported ones when empty.
+	Algorithms []string
+	// AllowMissingExpiry accepts tokens without an exp claim.
+	AllowMissingExpiry bool
+}
+
+// Validate verifies the token signature and registered claims.
+func (v *JWTValidator) Validate(ctx context.Context, token string) (jwt.Claims, error) {
+	if v.Keys == nil {
+		return nil, fmt.Errorf("%w: no verification keys configured", ErrInvalidToken)
+	}
+	header, claims, err := jwt.Parse(ctx, token, v.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(v.Algorithms) > 0 && !contains(v.Algorithms, header.Alg) {
+		return nil, fmt.Errorf("%w: algorithm %s not allowed", ErrInvalidToken, header.Alg)
+	}
+	if _, ok := claims["exp"]; !ok && !v.AllowMissingExpiry {
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if err = claims.Validate(jwt.Expectations{Issuer: v.Issuer, Audience: v.Audience, Leeway: v.ClockSkew}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// StaticKeys returns a key resolver over a fixed kid → key map. Values are
+// public keys or, for HS256, []byte secrets. A token without kid is accepted
+// only when the map holds a single key.
+func StaticKeys(keys map[string]interface{}) jwt.KeyFunc {
+	return func(_ context.Context, header jwt.Header) (interface{}, error) {
+		if key, ok := keys[header.Kid]; ok {
+			return key, nil
+		}
+		if header.Kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key %q", header.Kid)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+)
+
+// MetadataPath is the well-known location of protected resource metadata.
+const MetadataPath = schema.ProtectedResourceMetadataPath
+
+// serveMetadata writes the metadata document for the resource named by the
+// path suffix, or for Policy.Metadata at the root location.
+func (s *Service) serveMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resourcePath := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, MetadataPath), "/")
+	var configured *ProtectedResourceMetadata
+	if resourcePath == "" {
+		if s.Policy != nil {
+			configured = s.Policy.Metadata
+		}
+	} else if s.Policy != nil {
+		configured = s.Policy.Resources[resourcePath]
+	}
+	if configured == nil && resourcePath != "" {
+		http.NotFound(w, r)
+		return
+	}
+	metadata, err := s.document(r, resourcePath, configured)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(metadata)
+}
+
+// document completes configured with defaults: the resource identifier is
+// derived from the request origin and path, bearer tokens go in the header,
+// and the document is signed when Policy.MetadataKeys is set. A signed
+// document must name a configured resource, since the request origin is
+// chosen by the caller.
+func (s *Service) document(r *http.Request, resourcePath string, configured *ProtectedResourceMetadata) (*ProtectedResourceMetadata, error) {
+	metadata := &ProtectedResourceMetadata{}
+	if configured != nil {
+		*metadata = *configured
+	}
+	signing := s.Policy != nil && s.Policy.MetadataKeys != nil
+	if metadata.Resource == "" {
+		if signing {
+			return nil, errors.New("auth: signed metadata requires a configured resource")
+		}
+		metadata.Resource = s.requestOrigin(r) + resourcePath
+	}
+	if len(metadata.BearerMethodsSupported) == 0 {
+		metadata.BearerMethodsSupported = []string{"header"}
+	}
+	metadata.SignedMetadata = ""
+	if !signing {
+		return metadata, nil
+	}
+	signed, err := signMetadata(s.Policy.MetadataKeys, metadata)
+	if err != nil {
+		return nil, err
+	}
+	metadata.SignedMetadata = signed
+	return metadata, nil
+}
+
+// signMetadata returns a JWT asserting every metadata value (RFC 9728
+// section 2.2). The issuer is Issuer, or the resource itself.
+func signMetadata(keys *jwt.KeySet, metadata *ProtectedResourceMetadata) (string, error) {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	claims := jwt.Claims{}
+	if err = json.Unmarshal(data, &claims); err != nil {
+		return "", err
+	}
+	claims["iss"] = metadata.Resource
+	if metadata.Issuer != "" {
+		claims["iss"] = metadata.Issuer
+	}
+	claims["iat"] = time.Now().Unix()
+	return keys.Sign(claims)
+}
+
+// metadataFor returns the resource path and metadata protecting requestPath:
+// the longest matching Policy.Resources prefix, else Policy.Metadata.
+func (s *Service) metadataFor(requestPath string) (string, *ProtectedResourceMetadata) {
+	if s.Policy == nil {
+		return "", nil
+	}
+	match := ""
+	for prefix := range s.Policy.Resources {
+		if len(prefix) > len(match) && (requestPath == prefix || strings.HasPrefix(requestPath, strings.TrimSuffix(prefix, "/")+"/")) {
+			match = prefix
+		}
+	}
+	if match != "" {
+		return match, s.Policy.Resources[match]
+	}
+	return "", s.Policy.Metadata
+}
+
+// resourceMetadataURL returns the absolute metadata URL of the resource
+// protecting r, path-suffixed for Policy.Resources.
+func (s *Service) resourceMetadataURL(r *http.Request) string {
+	resourcePath, _ := s.metadataFor(r.URL.Path)
+	return s.requestOrigin(r) + MetadataPath + resourcePath
+}
+
+// requestOrigin returns the scheme and host r was sent to. X-Forwarded-Proto
+// and X-Forwarded-Host are only honored from Policy.TrustedProxies.
+func (s *Service) requestOrigin(r *http.Request) string {
+	proto, host := "http", r.Host
+	if r.TLS != nil {
+		proto = "https"
+	}
+	if s.Policy == nil || !FromTrustedProxy(r, s.Policy.TrustedProxies) {
+		return proto + "://" + host
+	}
+	if forwarded := strings.ToLower(r.Header.Get("X-Forwarded-Proto")); forwarded == "http" || forwarded == "https" {
+		proto = forwarded
+	}
+	return proto + "://" + headerOrDefault(r, "X-Forwarded-Host", host)
+}
+
+// FromTrustedProxy reports whether the peer of r matches one of proxies,
+// given as IP addresses or CIDR ranges.
+func FromTrustedProxy(r *http.Request, proxies []string) bool {
+	return TrustedProxy(r.RemoteAddr, proxies)
+}
+
+// TrustedProxy reports whether addr, an IP address with or without a port,
+// matches one of proxies, given as IP addresses or CIDR ranges.
+func TrustedProxy(addr string, proxies []string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,14 @@
 package auth
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
 )
 
 // Service provides HTTP middleware and metadata endpoint.
@@ -13,77 +17,220 @@ type Service struct {
 	// Optional token sources used when Authorization header is missing.
 	AccessSource AccessTokenSource
 	IDSource     IDTokenSource
+	// Validator, when set, validates bearer tokens; without it bearer tokens
+	// are rejected unless AcceptAnyBearer is set.
+	Validator TokenValidator
+	// Card, when set, makes the middleware enforce the card's security
+	// requirements instead of requiring a bearer token.
+	Card func() schema.AgentCard
+	// AcceptAnyBearer accepts any bearer token, for the default bearer
+	// requirement and for card bearer, oauth2 and openIdConnect schemes, when
+	// no Validator or bearer authenticator is set. Otherwise bearer tokens are
+	// rejected. Meant for demos only.
+	AcceptAnyBearer bool
+	// Authenticators verify credentials by kind (KindBearer, KindAPIKey,
+	// KindBasic, KindMutualTLS) for schemes declared in the card.
+	Authenticators map[string]Authenticator
+	// Logger receives dry-run decisions and the reasons bearer tokens were
+	// rejected; log.Default() when nil.
+	Logger *log.Logger
 }
 
 func NewService(p *Policy) *Service { return &Service{Policy: p} }
 
-// RegisterHandlers registers metadata endpoint.
+// RegisterHandlers registers the protected resource metadata endpoints: the
+// root document for Policy.Metadata and a path-suffixed one per Policy.Resources.
 func (s *Service) RegisterHandlers(mux *http.ServeMux) {
-	mux.HandleFunc("/.well-known/oauth-protected-resource", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		if s.Policy != nil && s.Policy.Metadata != nil {
-			_ = json.NewEncoder(w).Encode(s.Policy.Metadata)
-			return
-		}
-		_ = json.NewEncoder(w).Encode(&ProtectedResourceMetadata{Resource: "default"})
-	})
+	mux.HandleFunc(MetadataPath, s.serveMetadata)
+	mux.HandleFunc(MetadataPath+"/", s.serveMetadata)
 }
 
-// Middleware enforces Bearer auth for protected A2A HTTP endpoints.
-    // Bypasses metadata endpoints and the agent card.
+// Middleware authenticates A2A HTTP requests according to the first matching
+// Policy rule: by default a Bearer token or, when Card is set, the security
+// requirements the card declares. Metadata endpoints and the agent card are open.
 func (s *Service) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-
-		// Bypass OPTIONS, agent card, and metadata endpoint
-        if r.Method == http.MethodOptions || path == "/.well-known/oauth-protected-resource" || path == "/.well-known/agent-card.json" {
-            next.ServeHTTP(w, r)
-            return
-        }
-		if s.Policy != nil && s.Policy.ExcludePrefix != "" && strings.HasPrefix(path, s.Policy.ExcludePrefix) {
-			next.ServeHTTP(w, r)
-			return
+		rule := s.rule(r)
+		authenticated, denied := r, (*denial)(nil)
+		if rule.Access != AccessAnonymous {
+			authenticated, denied = s.authenticate(r, rule)
+			if denied != nil && denied.noCredentials && rule.Access == AccessOptional {
+				authenticated, denied = r, nil
+			}
 		}
-
-		// Require Authorization: Bearer ... for all other A2A routes
-		authz := r.Header.Get("Authorization")
-		if hasBearer(authz) {
-			// Attach token to context for downstream use
-			ctx := WithToken(r.Context(), &Token{Raw: authz})
-			r = r.WithContext(ctx)
-			next.ServeHTTP(w, r)
+		if route := s.scopeRoute(r); denied == nil && route != nil {
+			principal, _ := PrincipalFromContext(authenticated.Context())
+			if !route.Satisfied(principal) {
+				denied = insufficientScope(route.Scopes)
+			}
+		}
+		s.record(r, rule, authenticated, denied)
+		if denied != nil && !(s.Policy != nil && s.Policy.DryRun) {
+			denied.write(w)
 			return
 		}
+		next.ServeHTTP(w, authenticated)
+	})
+}
 
+// denial is a rejected authentication, written as a JSON error response.
+type denial struct {
+	status     int
+	challenges []string
+	body       string
+	reason     string
+	// noCredentials is set when the request presented no credentials at all.
+	noCredentials bool
+}
+
+func (d *denial) write(w http.ResponseWriter) {
+	for _, challenge := range d.challenges {
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(d.status)
+	_, _ = w.Write([]byte(d.body))
+}
+
+// scopeRoute returns the Policy.Scopes route matching r, or nil.
+func (s *Service) scopeRoute(r *http.Request) *RouteRequirement {
+	if s.Policy == nil {
+		return nil
+	}
+	return s.Policy.Scopes.route(r)
+}
+
+func insufficientScope(scopes []string) *denial {
+	return &denial{
+		status:     http.StatusForbidden,
+		challenges: []string{InsufficientScopeChallenge(scopes)},
+		body:       `{"error":"insufficient_scope"}`,
+		reason:     "insufficient scope",
+	}
+}
+
+// authenticate returns r carrying the authenticated principal, or why it was rejected.
+func (s *Service) authenticate(r *http.Request, rule Rule) (*http.Request, *denial) {
+	if len(rule.Authenticators) > 0 {
+		return s.authenticateKinds(r, rule.Authenticators)
+	}
+	if s.Card != nil {
+		return s.authenticateCard(r, s.Card())
+	}
+	// Require Authorization: Bearer ..., or credentials of a registered authenticator
+	var kinds []string
+	for _, kind := range []string{KindAPIKey, KindBasic, KindMutualTLS} {
+		if _, ok := s.Authenticators[kind]; ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	if !hasBearer(r.Header.Get("Authorization")) {
+		authenticated, denied := s.authenticateKinds(r, kinds)
+		if denied == nil || !denied.noCredentials {
+			return authenticated, denied
+		}
 		// Attempt to acquire a token from configured sources
-		if s.AccessSource != nil || s.IDSource != nil {
-			resource := "a2a"
-			if s.Policy != nil && s.Policy.Metadata != nil && s.Policy.Metadata.Resource != "" {
-				resource = s.Policy.Metadata.Resource
-			}
-			var token string
-			var err error
-			if s.Policy != nil && s.Policy.UseIDToken && s.IDSource != nil {
-				token, err = s.IDSource.IDToken(r.Context(), r, resource)
-			} else if s.AccessSource != nil {
-				token, err = s.AccessSource.AccessToken(r.Context(), r, resource)
-			}
-			if err == nil && strings.TrimSpace(token) != "" {
-				authz = "Bearer " + strings.TrimSpace(token)
-				r.Header.Set("Authorization", authz)
-				ctx := WithToken(r.Context(), &Token{Raw: authz})
-				r = r.WithContext(ctx)
-				next.ServeHTTP(w, r)
-				return
+		token := s.acquireToken(r)
+		if token == "" {
+			// Missing creds: 401 with WWW-Authenticate and resource metadata
+			denied.challenges = append([]string{s.wwwAuthenticateHeader(r)}, denied.challenges...)
+			denied.body = `{"error":"Unauthorized: Bearer token required"}`
+			return r, denied
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.authenticateKinds(r, []string{KindBearer})
+}
+
+// authenticateKinds accepts the credentials of the first of kinds the request presents.
+func (s *Service) authenticateKinds(r *http.Request, kinds []string) (*http.Request, *denial) {
+	for _, kind := range kinds {
+		authenticator := s.authenticator(kind)
+		if authenticator == nil {
+			continue
+		}
+		principal, err := authenticator.Authenticate(r, schema.SecurityScheme{})
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err == nil {
+			return withPrincipal(r, principal), nil
+		}
+		if kind == KindBearer {
+			// RFC 6750 section 3.1
+			s.logInvalidToken(r, err)
+			return r, &denial{
+				status:     http.StatusUnauthorized,
+				challenges: []string{s.wwwAuthenticateHeader(r) + invalidTokenChallenge},
+				body:       `{"error":"invalid_token"}`,
+				reason:     err.Error(),
 			}
 		}
+		return r, &denial{status: http.StatusUnauthorized, body: `{"error":"invalid_credentials"}`, reason: err.Error()}
+	}
+	denied := &denial{status: http.StatusUnauthorized, body: `{"error":"Unauthorized: credentials required"}`, reason: "no credentials", noCredentials: true}
+	if contains(kinds, KindBearer) {
+		denied.challenges = append(denied.challenges, s.wwwAuthenticateHeader(r))
+	}
+	if contains(kinds, KindBasic) {
+		denied.challenges = append(denied.challenges, `Basic realm="a2a", charset="UTF-8"`)
+	}
+	return r, denied
+}
 
-		// Missing/invalid creds: 401 with WWW-Authenticate and resource metadata
-		w.Header().Set("WWW-Authenticate", s.wwwAuthenticateHeader(r))
-		w.WriteHeader(http.StatusUnauthorized)
-		// Return a small JSON message (optional)
-		_, _ = w.Write([]byte(`{"error":"Unauthorized: Bearer token required"}`))
-	})
+// withPrincipal attaches the principal, its claims and any bearer token to r.
+func withPrincipal(r *http.Request, principal *Principal) *http.Request {
+	ctx := r.Context()
+	if authz := r.Header.Get("Authorization"); hasBearer(authz) {
+		ctx = WithToken(ctx, &Token{Raw: authz})
+	}
+	if principal.Claims != nil {
+		ctx = WithClaims(ctx, jwt.Claims(principal.Claims))
+	}
+	return r.WithContext(WithPrincipal(ctx, principal))
+}
+
+// acquireToken obtains a token from the configured sources, if any.
+func (s *Service) acquireToken(r *http.Request) string {
+	if s.AccessSource == nil && s.IDSource == nil {
+		return ""
+	}
+	resource := "a2a"
+	if s.Policy != nil && s.Policy.Metadata != nil && s.Policy.Metadata.Resource != "" {
+		resource = s.Policy.Metadata.Resource
+	}
+	var token string
+	var err error
+	if s.Policy != nil && s.Policy.UseIDToken && s.IDSource != nil {
+		token, err = s.IDSource.IDToken(r.Context(), r, resource)
+	} else if s.AccessSource != nil {
+		token, err = s.AccessSource.AccessToken(r.Context(), r, resource)
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// invalidTokenChallenge is appended to the Bearer challenge when a token is
+// rejected. The description is fixed so validator errors never reach the
+// caller; logInvalidToken records them instead.
+const invalidTokenChallenge = `, error="invalid_token", error_description="invalid or expired token"`
+
+func (s *Service) logInvalidToken(r *http.Request, err error) {
+	s.logger().Printf("auth: %s %s invalid bearer token: %v", r.Method, r.URL.Path, err)
+}
+
+func (s *Service) logger() *log.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return log.Default()
+}
+
+// quoteEscape replaces characters RFC 6750 disallows in quoted challenge values.
+func quoteEscape(v string) string {
+	return strings.NewReplacer(`\`, "/", `"`, "'").Replace(v)
 }
 
 func hasBearer(h string) bool {
@@ -95,13 +242,10 @@ func hasBearer(h string) bool {
 }
 
 func (s *Service) wwwAuthenticateHeader(r *http.Request) string {
-	// Build resource_metadata absolute URL
-	proto := headerOrDefault(r, "X-Forwarded-Proto", "http")
-	host := headerOrDefault(r, "X-Forwarded-Host", r.Host)
-	metaURL := fmt.Sprintf("%s://%s/.well-known/oauth-protected-resource", proto, host)
+	metaURL := s.resourceMetadataURL(r)
 	scope := ""
-	if s.Policy != nil && s.Policy.Metadata != nil && len(s.Policy.Metadata.ScopesSupported) > 0 {
-		scope = fmt.Sprintf(`, scope="%s"`, strings.Join(s.Policy.Metadata.ScopesSupported, " "))
+	if _, metadata := s.metadataFor(r.URL.Path); metadata != nil && len(metadata.ScopesSupported) > 0 {
+		scope = fmt.Sprintf(`, scope="%s"`, strings.Join(metadata.ScopesSupported, " "))
 	}
 	return fmt.Sprintf(`Bearer resource_metadata="%s"%s`, metaURL, scope)
 }
@@ -0,0 +1,664 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/client"
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+)
+
+func TestMiddleware_JWTValidation(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	hsKey := []byte("shared-secret")
+	validator := &JWTValidator{
+		Keys:      StaticKeys(map[string]interface{}{"ec": &ecKey.PublicKey, "hs": hsKey}),
+		Issuer:    "https://idp.example.com",
+		Audience:  "a2a",
+		ClockSkew: 30 * time.Second,
+	}
+	svc := &Service{Validator: validator}
+	var gotSubject string
+	handler := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := PrincipalFromContext(r.Context()); ok && principal.Method == MethodJWT {
+			gotSubject = principal.Subject
+		}
+	}))
+
+	now := time.Now()
+	valid := jwt.Claims{"iss": "https://idp.example.com", "aud": "a2a", "sub": "alice", "scope": "tasks.read tasks.write", "exp": now.Add(time.Minute).Unix()}
+	sign := func(key jwt.SigningKey, claims jwt.Claims) string {
+		token, err := jwt.Sign(key, claims)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+	with := func(name string, value interface{}) jwt.Claims {
+		out := jwt.Claims{}
+		for k, v := range valid {
+			out[k] = v
+		}
+		if value == nil {
+			delete(out, name)
+		} else {
+			out[name] = value
+		}
+		return out
+	}
+	testCases := []struct {
+		description string
+		token       string
+		expect      int
+	}{
+		{description: "es256", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, valid), expect: http.StatusOK},
+		{description: "hs256", token: sign(jwt.SigningKey{ID: "hs", Key: hsKey}, valid), expect: http.StatusOK},
+		{description: "expired", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, with("exp", now.Add(-time.Minute).Unix())), expect: http.StatusUnauthorized},
+		{description: "within skew", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, with("exp", now.Add(-10*time.Second).Unix())), expect: http.StatusOK},
+		{description: "not yet valid", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, with("nbf", now.Add(time.Hour).Unix())), expect: http.StatusUnauthorized},
+		{description: "wrong issuer", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, with("iss", "https://evil.example.com")), expect: http.StatusUnauthorized},
+		{description: "wrong audience", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, with("aud", "other")), expect: http.StatusUnauthorized},
+		{description: "missing exp", token: sign(jwt.SigningKey{ID: "ec", Key: ecKey}, with("exp", nil)), expect: http.StatusUnauthorized},
+		{description: "unknown kid", token: sign(jwt.SigningKey{ID: "other", Key: ecKey}, valid), expect: http.StatusUnauthorized},
+		{description: "garbage", token: "not-a-jwt", expect: http.StatusUnauthorized},
+	}
+	for _, testCase := range testCases {
+		gotSubject = ""
+		req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+		req.Header.Set("Authorization", "Bearer "+testCase.token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, rr.Code, testCase.expect)
+			continue
+		}
+		if testCase.expect == http.StatusOK && gotSubject != "alice" {
+			t.Errorf("%s: principal not in context", testCase.description)
+		}
+		if testCase.expect == http.StatusUnauthorized && !strings.Contains(rr.Header().Get("WWW-Authenticate"), `error="invalid_token", error_description="invalid or expired token"`) {
+			t.Errorf("%s: challenge=%q", testCase.description, rr.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestPrincipalFromClaims(t *testing.T) {
+	p := PrincipalFromClaims(jwt.Claims{"sub": "svc", "tid": "acme", "scope": "a b", "scp": []interface{}{"c"}}, MethodJWT)
+	if p.Subject != "svc" || p.Tenant != "acme" || !p.HasScope("a") || !p.HasScope("c") || p.HasScope("d") {
+		t.Fatalf("principal=%+v", p)
+	}
+}
+
+func TestScopePolicy_Middleware(t *testing.T) {
+	policy := &ScopePolicy{Routes: []RouteRequirement{
+		{Method: http.MethodGet, Path: "/v1/tasks/public", Requirement: Requirement{}},
+		{Path: "/v1/tasks/*", Requirement: Requirement{Scopes: []string{"tasks.read"}}},
+	}}
+	handler := policy.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	testCases := []struct {
+		description string
+		method      string
+		path        string
+		principal   *Principal
+		expect      int
+	}{
+		{description: "first match wins", method: http.MethodGet, path: "/v1/tasks/public", expect: http.StatusOK},
+		{description: "prefix without scope", method: http.MethodGet, path: "/v1/tasks/t-1", principal: &Principal{}, expect: http.StatusForbidden},
+		{description: "prefix with scope", method: http.MethodPost, path: "/v1/tasks/t-1", principal: &Principal{Scopes: []string{"tasks.read"}}, expect: http.StatusOK},
+		{description: "no principal", method: http.MethodGet, path: "/v1/tasks/t-1", expect: http.StatusForbidden},
+		{description: "unmatched route", method: http.MethodGet, path: "/v1/message:send", expect: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.path, nil)
+		if testCase.principal != nil {
+			req = req.WithContext(WithPrincipal(req.Context(), testCase.principal))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, rr.Code, testCase.expect)
+		}
+		if rr.Code == http.StatusForbidden && rr.Header().Get("WWW-Authenticate") != `Bearer error="insufficient_scope", scope="tasks.read"` {
+			t.Errorf("%s: challenge=%q", testCase.description, rr.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestMiddleware_CardSecurity(t *testing.T) {
+	hsKey := []byte("shared-secret")
+	card := schema.AgentCard{
+		Name: "agent",
+		SecuritySchemes: map[string]schema.SecurityScheme{
+			"oauth": {Type: schema.SecuritySchemeOAuth2},
+			"key":   {Type: schema.SecuritySchemeAPIKey, In: "query", Name: "api_key"},
+			"basic": {Type: schema.SecuritySchemeHTTP, Scheme: "basic"},
+			"mtls":  {Type: schema.SecuritySchemeMutualTLS},
+		},
+		// oauth with tasks.read OR (api key AND basic) OR mtls
+		Security: []map[string][]string{{"oauth": {"tasks.read"}}, {"key": {}, "basic": {}}, {"mtls": {}}},
+	}
+	svc := &Service{
+		Card:      func() schema.AgentCard { return card },
+		Validator: &JWTValidator{Keys: StaticKeys(map[string]interface{}{"hs": hsKey}), AllowMissingExpiry: true},
+		Authenticators: map[string]Authenticator{
+			KindAPIKey: &APIKeyAuthenticator{Lookup: func(_ context.Context, key string) (*Principal, error) {
+				if key != "k1" {
+					return nil, errors.New("unknown key")
+				}
+				return &Principal{Subject: "svc", Tenant: "acme", Scopes: []string{"tasks.read"}, Audience: []string{"a2a"}}, nil
+			}},
+			KindBasic: &BasicAuthenticator{Verify: func(_ context.Context, username, password string) (*Principal, error) {
+				if password != "pw" {
+					return nil, errors.New("bad password")
+				}
+				return &Principal{Subject: username}, nil
+			}},
+		},
+	}
+	var got *Principal
+	handler := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = PrincipalFromContext(r.Context())
+	}))
+	token := func(scope string) string {
+		raw, _ := jwt.Sign(jwt.SigningKey{ID: "hs", Key: hsKey}, jwt.Claims{"sub": "alice", "scope": scope})
+		return raw
+	}
+	testCases := []struct {
+		description string
+		prepare     func(r *http.Request)
+		expect      int
+		subject     string
+		tenant      string
+		challenges  []string
+	}{
+		{description: "no credentials", prepare: func(r *http.Request) {}, expect: http.StatusUnauthorized,
+			challenges: []string{`Bearer resource_metadata="http://example.com/.well-known/oauth-protected-resource", scope="tasks.read"`, `Basic realm="agent", charset="UTF-8"`, `ApiKey realm="agent", in="query", name="api_key"`}},
+		{description: "oauth", prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token("tasks.read")) }, expect: http.StatusOK, subject: "alice"},
+		{description: "oauth without scope", prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token("other")) }, expect: http.StatusForbidden},
+		{description: "invalid token", prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer junk") }, expect: http.StatusUnauthorized},
+		{description: "api key and basic", prepare: func(r *http.Request) {
+			r.URL.RawQuery = "api_key=k1"
+			r.SetBasicAuth("bob", "pw")
+		}, expect: http.StatusOK, subject: "bob", tenant: "acme"},
+		{description: "api key alone", prepare: func(r *http.Request) { r.URL.RawQuery = "api_key=k1" }, expect: http.StatusUnauthorized},
+		{description: "mtls", prepare: func(r *http.Request) {
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "client-1"}}}}}
+		}, expect: http.StatusOK, subject: "client-1"},
+	}
+	for _, testCase := range testCases {
+		got = nil
+		req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+		testCase.prepare(req)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, rr.Code, testCase.expect)
+			continue
+		}
+		if testCase.subject != "" && (got == nil || got.Subject != testCase.subject || got.Tenant != testCase.tenant) {
+			t.Errorf("%s: principal=%+v", testCase.description, got)
+		}
+		if testCase.challenges != nil && strings.Join(rr.Header().Values("WWW-Authenticate"), "|") != strings.Join(testCase.challenges, "|") {
+			t.Errorf("%s: challenges=%q", testCase.description, rr.Header().Values("WWW-Authenticate"))
+		}
+	}
+	if challenge := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+		req.Header.Set("Authorization", "Bearer junk")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Header().Get("WWW-Authenticate")
+	}(); !strings.Contains(challenge, `error="invalid_token"`) {
+		t.Errorf("invalid token challenge=%q", challenge)
+	}
+
+	// without a validator, bearer schemes fail closed unless explicitly opened
+	for _, acceptAny := range []bool{false, true} {
+		open := &Service{Card: func() schema.AgentCard { return card }, AcceptAnyBearer: acceptAny}
+		req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+		req.Header.Set("Authorization", "Bearer anything")
+		rr := httptest.NewRecorder()
+		open.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rr, req)
+		if expect := map[bool]int{false: http.StatusUnauthorized, true: http.StatusForbidden}[acceptAny]; rr.Code != expect {
+			t.Errorf("accept any bearer=%v: status=%d want %d", acceptAny, rr.Code, expect)
+		}
+		// the default bearer requirement and a registered authenticator fail closed too
+		for _, svc := range []*Service{{AcceptAnyBearer: acceptAny}, {Authenticators: map[string]Authenticator{KindBearer: &BearerAuthenticator{AcceptAny: acceptAny}}}} {
+			rr = httptest.NewRecorder()
+			svc.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rr, req)
+			if expect := map[bool]int{false: http.StatusUnauthorized, true: http.StatusOK}[acceptAny]; rr.Code != expect {
+				t.Errorf("default bearer, accept any=%v: status=%d want %d", acceptAny, rr.Code, expect)
+			}
+		}
+	}
+}
+
+func TestMiddleware_APIKeyAndBasic(t *testing.T) {
+	if got := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)); got != "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783" {
+		t.Fatalf("pbkdf2 vector=%s", got)
+	}
+	keys := NewKeyStore(
+		&APIKey{ID: "ci", Hash: HashAPIKey("k-live"), Tenant: "acme", Scopes: []string{"tasks.read"}},
+		&APIKey{ID: "old", Hash: HashAPIKey("k-old"), ExpiresAt: time.Now().Add(-time.Minute)},
+	)
+	path := filepath.Join(t.TempDir(), "credentials")
+	hash, _ := HashPassword("secret", 1000)
+	if err := os.WriteFile(path, []byte("# users\nbob:"+hash+":tasks.read,tasks.write:admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := NewCredentialsFile(path)
+	if err != nil {
+		t.Fatalf("load credentials: %v", err)
+	}
+	credentials.ReloadInterval = -1
+	svc := &Service{Authenticators: map[string]Authenticator{
+		KindAPIKey: &APIKeyAuthenticator{Lookup: keys.Lookup, Name: "api_key", In: "cookie"},
+		KindBasic:  &BasicAuthenticator{Verify: credentials.Verify},
+	}}
+	var got *Principal
+	handler := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = PrincipalFromContext(r.Context())
+	}))
+	cookie := func(value string) func(r *http.Request) {
+		return func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "api_key", Value: value}) }
+	}
+	basic := func(username, password string) func(r *http.Request) {
+		return func(r *http.Request) { r.SetBasicAuth(username, password) }
+	}
+	testCases := []struct {
+		description string
+		prepare     func(r *http.Request)
+		expect      int
+		subject     string
+	}{
+		{description: "api key", prepare: cookie("k-live"), expect: http.StatusOK, subject: "ci"},
+		{description: "unknown api key", prepare: cookie("k-nope"), expect: http.StatusUnauthorized},
+		{description: "expired api key", prepare: cookie("k-old"), expect: http.StatusUnauthorized},
+		{description: "basic", prepare: basic("bob", "secret"), expect: http.StatusOK, subject: "bob"},
+		{description: "wrong password", prepare: basic("bob", "guess"), expect: http.StatusUnauthorized},
+		{description: "unknown user", prepare: basic("mallory", "secret"), expect: http.StatusUnauthorized},
+		{description: "no credentials", prepare: func(r *http.Request) {}, expect: http.StatusUnauthorized},
+	}
+	for _, testCase := range testCases {
+		got = nil
+		req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+		testCase.prepare(req)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, rr.Code, testCase.expect)
+			continue
+		}
+		if testCase.subject != "" && (got == nil || got.Subject != testCase.subject) {
+			t.Errorf("%s: principal=%+v", testCase.description, got)
+		}
+	}
+	// unknown users pay for a hash as slow as the known ones
+	if parts := strings.Split(credentials.dummyHash, "$"); len(parts) != 4 || parts[1] != "1000" || CheckPassword(credentials.dummyHash, "") {
+		t.Fatalf("dummy hash=%s", credentials.dummyHash)
+	}
+	// an authenticator without Lookup rejects keys instead of panicking
+	noLookup := (&Service{Authenticators: map[string]Authenticator{KindAPIKey: &APIKeyAuthenticator{}}}).Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+	req.Header.Set(DefaultAPIKeyHeader, "k-live")
+	rr := httptest.NewRecorder()
+	noLookup.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("nil lookup: status=%d", rr.Code)
+	}
+	if _, err := keys.Lookup(context.Background(), "k-live"); err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	keys.Revoke("ci")
+	if _, err := keys.Lookup(context.Background(), "k-live"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("revoked key err=%v", err)
+	}
+
+	// rotate the password on disk; the file is picked up without a restart
+	hash, _ = HashPassword("rotated", 1000)
+	if err := os.WriteFile(path, []byte("bob:"+hash+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(path, future, future)
+	if _, err := credentials.Verify(context.Background(), "bob", "secret"); err == nil {
+		t.Fatalf("old password accepted after reload")
+	}
+	if p, err := credentials.Verify(context.Background(), "bob", "rotated"); err != nil || p.Subject != "bob" {
+		t.Fatalf("rotated password: %v", err)
+	}
+}
+
+func TestMiddleware_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test-ca"}, NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	caCert, _ := x509.ParseCertificate(caDER)
+	issue := func(serial int64, template *x509.Certificate) (tls.Certificate, []byte, []byte) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template.SerialNumber, template.NotBefore, template.NotAfter = big.NewInt(serial), time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+		der, _ := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		keyDER, _ := x509.MarshalPKCS8PrivateKey(key)
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+		cert, _ := tls.X509KeyPair(certPEM, keyPEM)
+		return cert, certPEM, keyPEM
+	}
+	_, serverPEM, serverKeyPEM := issue(2, &x509.Certificate{Subject: pkix.Name{CommonName: "localhost"}, IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	spiffe, _ := url.Parse("spiffe://prod.example.com/ns/ci/sa/agent")
+	clientCert, _, _ := issue(3, &x509.Certificate{Subject: pkix.Name{CommonName: "agent", Organization: []string{"acme"}}, URIs: []*url.URL{spiffe}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	otherCert, _, _ := issue(4, &x509.Certificate{Subject: pkix.Name{CommonName: "other"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	options := &TLSOptions{
+		CertFile:     write("server.pem", serverPEM),
+		KeyFile:      write("server.key", serverKeyPEM),
+		ClientCAFile: write("ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+	}
+	config, err := options.Config()
+	if err != nil {
+		t.Fatalf("tls config: %v", err)
+	}
+	svc := &Service{Authenticators: map[string]Authenticator{KindMutualTLS: &MutualTLSAuthenticator{TrustDomains: []string{"prod.example.com"}}}}
+	ts := httptest.NewUnstartedServer(svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := PrincipalFromContext(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"id":%q,"contextId":%q,"status":{"state":"completed"}}}`, p.Subject, p.Tenant)
+	})))
+	ts.TLS = config
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	task, err := client.New(ts.URL, client.WithClientCertificate(clientCert), client.WithRootCAs(roots)).GetTask(context.Background(), "t-1")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if task.ID != "spiffe://prod.example.com/ns/ci/sa/agent" || task.ContextID == nil || *task.ContextID != "acme" {
+		t.Fatalf("principal=%+v", task)
+	}
+	if _, err = client.New(ts.URL, client.WithClientCertificate(otherCert), client.WithRootCAs(roots)).GetTask(context.Background(), "t-1"); err == nil {
+		t.Fatalf("certificate outside trust domain accepted")
+	}
+	if _, err = client.New(ts.URL, client.WithRootCAs(roots)).GetTask(context.Background(), "t-1"); err == nil {
+		t.Fatalf("request without certificate accepted")
+	}
+}
+
+func TestMiddleware_Rules(t *testing.T) {
+	hsKey := []byte("shared-secret")
+	keys := NewKeyStore(&APIKey{ID: "ci", Hash: HashAPIKey("k1")})
+	policy := &Policy{Rules: []Rule{
+		{Path: "/healthz", Access: AccessAnonymous},
+		{Path: "/.well-known/agent-card.json", Authenticators: []string{KindBearer}},
+		{Path: "/v1/*", Authenticators: []string{KindAPIKey}},
+		{Method: http.MethodGet, Path: "/a2a/public", Access: AccessOptional},
+		{Path: "/a2a*", Authenticators: []string{KindBearer}},
+	}, Scopes: &ScopePolicy{Routes: []RouteRequirement{
+		{Method: http.MethodGet, Path: "/a2a/public"},
+		{Path: "/a2a*", Requirement: Requirement{Scopes: []string{"a2a"}}},
+	}}}
+	svc := &Service{
+		Policy:         policy,
+		Validator:      &JWTValidator{Keys: StaticKeys(map[string]interface{}{"hs": hsKey}), AllowMissingExpiry: true},
+		Authenticators: map[string]Authenticator{KindAPIKey: &APIKeyAuthenticator{Lookup: keys.Lookup}},
+	}
+	var decisions []Decision
+	policy.OnDecision = func(d Decision) { decisions = append(decisions, d) }
+	handler := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	bearer := func(scope string) func(r *http.Request) {
+		raw, _ := jwt.Sign(jwt.SigningKey{ID: "hs", Key: hsKey}, jwt.Claims{"sub": "alice", "scope": scope})
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+raw) }
+	}
+	apiKey := func(r *http.Request) { r.Header.Set(DefaultAPIKeyHeader, "k1") }
+	none := func(r *http.Request) {}
+	testCases := []struct {
+		description string
+		method      string
+		path        string
+		prepare     func(r *http.Request)
+		expect      int
+	}{
+		{description: "health open", method: http.MethodGet, path: "/healthz", prepare: none, expect: http.StatusOK},
+		{description: "agent card protected", method: http.MethodGet, path: "/.well-known/agent-card.json", prepare: none, expect: http.StatusUnauthorized},
+		{description: "agent card with token", method: http.MethodGet, path: "/.well-known/agent-card.json", prepare: bearer(""), expect: http.StatusOK},
+		{description: "jwks still open by default", method: http.MethodGet, path: "/.well-known/jwks.json", prepare: none, expect: http.StatusOK},
+		{description: "rest with api key", method: http.MethodPost, path: "/v1/message:send", prepare: apiKey, expect: http.StatusOK},
+		{description: "rest rejects bearer", method: http.MethodPost, path: "/v1/message:send", prepare: bearer("a2a"), expect: http.StatusUnauthorized},
+		{description: "a2a rejects api key", method: http.MethodPost, path: "/a2a", prepare: apiKey, expect: http.StatusUnauthorized},
+		{description: "a2a without scope", method: http.MethodPost, path: "/a2a", prepare: bearer("other"), expect: http.StatusForbidden},
+		{description: "a2a with scope", method: http.MethodPost, path: "/a2a", prepare: bearer("a2a"), expect: http.StatusOK},
+		{description: "optional anonymous", method: http.MethodGet, path: "/a2a/public", prepare: none, expect: http.StatusOK},
+		{description: "optional invalid token", method: http.MethodGet, path: "/a2a/public", prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer junk") }, expect: http.StatusUnauthorized},
+		{description: "unmatched requires credentials", method: http.MethodPost, path: "/other", prepare: none, expect: http.StatusUnauthorized},
+	}
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.path, nil)
+		testCase.prepare(req)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, rr.Code, testCase.expect)
+		}
+	}
+	if len(decisions) != len(testCases) || decisions[1].Allowed || decisions[1].Rule != "/.well-known/agent-card.json" || decisions[2].Subject != "alice" {
+		t.Fatalf("decisions=%+v", decisions)
+	}
+
+	decisions = nil
+	policy.DryRun = true
+	req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || len(decisions) != 1 || decisions[0].Allowed || !decisions[0].DryRun || decisions[0].Status != http.StatusUnauthorized {
+		t.Fatalf("dry run status=%d decisions=%+v", rr.Code, decisions)
+	}
+
+	// without OnDecision, dry-run decisions and token errors go to Service.Logger
+	var logged bytes.Buffer
+	svc.Logger, policy.OnDecision = log.New(&logged, "", 0), nil
+	req = httptest.NewRequest(http.MethodPost, "/a2a", nil)
+	req.Header.Set("Authorization", "Bearer junk")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if out := logged.String(); !strings.Contains(out, "invalid bearer token") || !strings.Contains(out, "rule=/a2a* allowed=false status=401") {
+		t.Fatalf("log=%q", out)
+	}
+}
+
+func TestService_ResourceMetadata(t *testing.T) {
+	svc := &Service{Policy: &Policy{
+		Metadata:       &ProtectedResourceMetadata{ScopesSupported: []string{"default"}},
+		Resources:      map[string]*ProtectedResourceMetadata{"/tenants/acme": {Resource: "https://acme.example/tenants/acme", ScopesSupported: []string{"acme"}}},
+		TrustedProxies: []string{"10.0.0.0/8"},
+	}}
+	mux := http.NewServeMux()
+	svc.RegisterHandlers(mux)
+	mux.Handle("/", svc.Middleware(http.NotFoundHandler()))
+
+	var testCases = []struct {
+		description string
+		path        string
+		remote      string
+		proto       string
+		status      int
+		resource    string
+		challenge   string
+	}{
+		{description: "root document", path: MetadataPath, status: http.StatusOK, resource: "http://agent.test"},
+		{description: "path-suffixed document", path: MetadataPath + "/tenants/acme", status: http.StatusOK, resource: "https://acme.example/tenants/acme"},
+		{description: "unknown resource", path: MetadataPath + "/tenants/other", status: http.StatusNotFound},
+		{description: "root challenge", path: "/v1/tasks/t1", status: http.StatusUnauthorized, challenge: `Bearer resource_metadata="http://agent.test/.well-known/oauth-protected-resource", scope="default"`},
+		{description: "tenant challenge", path: "/tenants/acme/rpc", status: http.StatusUnauthorized, challenge: `Bearer resource_metadata="http://agent.test/.well-known/oauth-protected-resource/tenants/acme", scope="acme"`},
+		{description: "untrusted forwarded headers", path: MetadataPath, proto: "https", status: http.StatusOK, resource: "http://agent.test"},
+		{description: "trusted proxy", path: MetadataPath, remote: "10.1.2.3:5555", proto: "https", status: http.StatusOK, resource: "https://public.example"},
+		{description: "trusted proxy bad scheme", path: MetadataPath, remote: "10.1.2.3:5555", proto: "javascript", status: http.StatusOK, resource: "http://public.example"},
+		{description: "trusted proxy challenge", path: "/v1/tasks/t1", remote: "10.1.2.3:5555", proto: "https", status: http.StatusUnauthorized, challenge: `Bearer resource_metadata="https://public.example/.well-known/oauth-protected-resource", scope="default"`},
+	}
+	for _, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "http://agent.test"+testCase.path, nil)
+		if testCase.remote != "" {
+			req.RemoteAddr = testCase.remote
+		}
+		if testCase.proto != "" {
+			req.Header.Set("X-Forwarded-Proto", testCase.proto)
+			req.Header.Set("X-Forwarded-Host", "public.example")
+		}
+		mux.ServeHTTP(rec, req)
+		if rec.Code != testCase.status {
+			t.Fatalf("%s: status=%d", testCase.description, rec.Code)
+		}
+		if testCase.challenge != "" && rec.Header().Get("WWW-Authenticate") != testCase.challenge {
+			t.Fatalf("%s: challenge=%q", testCase.description, rec.Header().Get("WWW-Authenticate"))
+		}
+		if testCase.resource == "" {
+			continue
+		}
+		var metadata ProtectedResourceMetadata
+		if err := json.Unmarshal(rec.Body.Bytes(), &metadata); err != nil || metadata.Resource != testCase.resource || len(metadata.BearerMethodsSupported) != 1 || metadata.SignedMetadata != "" {
+			t.Fatalf("%s: metadata=%+v err=%v", testCase.description, metadata, err)
+		}
+	}
+
+	// a signed document never names a resource taken from the request
+	metaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys, _ := jwt.NewKeySet(jwt.SigningKey{ID: "meta", Key: metaKey})
+	svc.Policy.MetadataKeys = keys
+	for path, status := range map[string]int{MetadataPath: http.StatusInternalServerError, MetadataPath + "/tenants/acme": http.StatusOK} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://agent.test"+path, nil))
+		if rec.Code != status {
+			t.Fatalf("signed %s: status=%d", path, rec.Code)
+		}
+	}
+}
+
+func TestMiddleware_Introspection(t *testing.T) {
+	var calls int
+	var failing bool
+	exp := time.Now().Add(time.Hour).Unix()
+	as := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if id, secret, ok := r.BasicAuth(); !ok || id != "agent" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("token") {
+		case "opaque-live":
+			_, _ = fmt.Fprintf(w, `{"active":true,"sub":"alice","scope":"tasks.read tasks.write","aud":["https://agent.example","crm"],"exp":%d}`, exp)
+		case "opaque-other-aud":
+			_, _ = fmt.Fprintf(w, `{"active":true,"sub":"bob","aud":"crm","exp":%d}`, exp)
+		default:
+			_, _ = w.Write([]byte(`{"active":false}`))
+		}
+	}))
+	defer as.Close()
+
+	introspector := &IntrospectionValidator{Endpoint: as.URL, ClientID: "agent", ClientSecret: "s3cret", Audience: "https://agent.example"}
+	now := time.Now()
+	introspector.now = func() time.Time { return now }
+	svc := &Service{Authenticators: map[string]Authenticator{KindBearer: introspector}}
+	var got *Principal
+	handler := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = PrincipalFromContext(r.Context())
+	}))
+	call := func(token string) int {
+		got = nil
+		req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	testCases := []struct {
+		description string
+		token       string
+		expect      int
+		calls       int
+	}{
+		{description: "active token", token: "opaque-live", expect: http.StatusOK, calls: 1},
+		{description: "cached active token", token: "opaque-live", expect: http.StatusOK, calls: 1},
+		{description: "inactive token", token: "opaque-revoked", expect: http.StatusUnauthorized, calls: 2},
+		{description: "cached inactive token", token: "opaque-revoked", expect: http.StatusUnauthorized, calls: 2},
+		{description: "other audience", token: "opaque-other-aud", expect: http.StatusUnauthorized, calls: 3},
+	}
+	for _, testCase := range testCases {
+		if status := call(testCase.token); status != testCase.expect || calls != testCase.calls {
+			t.Fatalf("%s: status=%d calls=%d", testCase.description, status, calls)
+		}
+	}
+	if call("opaque-live"); got == nil || got.Subject != "alice" || got.Method != MethodIntrospection || !got.HasScope("tasks.write") || len(got.Audience) != 2 {
+		t.Fatalf("principal=%+v", got)
+	}
+
+	// results expire after their TTL and endpoint failures are not cached
+	now = now.Add(DefaultIntrospectionTTL + time.Second)
+	failing = true
+	if status := call("opaque-live"); status != http.StatusUnauthorized || calls != 4 {
+		t.Fatalf("failing endpoint: status=%d calls=%d", status, calls)
+	}
+	failing = false
+	if status := call("opaque-live"); status != http.StatusOK || calls != 5 {
+		t.Fatalf("recovered endpoint: status=%d calls=%d", status, calls)
+	}
+
+	// inactive tokens are bounded apart and never evict active ones
+	introspector.MaxEntries, introspector.MaxNegativeEntries = 2, 2
+	for _, token := range []string{"a", "b", "c", "d"} {
+		call(token)
+	}
+	if len(introspector.negative) > 2 || len(introspector.cache) != 1 {
+		t.Fatalf("cache size=%d negative=%d", len(introspector.cache), len(introspector.negative))
+	}
+	if status := call("opaque-live"); status != http.StatusOK || calls != 9 {
+		t.Fatalf("active token after flood: status=%d calls=%d", status, calls)
+	}
+
+	// a full cache drops expired entries, then those expiring soonest
+	introspector.store("expired", jwt.Claims{"sub": "x"}, now.Add(-time.Second))
+	introspector.store("later", jwt.Claims{"sub": "y"}, now.Add(2*time.Hour))
+	if _, ok := introspector.cache["expired"]; ok || len(introspector.cache) != 2 {
+		t.Fatalf("expired kept: %d", len(introspector.cache))
+	}
+	introspector.store("latest", jwt.Claims{"sub": "z"}, now.Add(3*time.Hour))
+	if _, ok := introspector.cache["later"]; !ok || len(introspector.cache) != 2 {
+		t.Fatalf("evicted the wrong entry: %v", introspector.cache)
+	}
+}
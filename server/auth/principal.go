@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/viant/a2a-protocol/jwt"
+)
+
+// Authentication methods reported in Principal.Method.
+const (
+	// MethodBearer is an opaque bearer token accepted without validation.
+	MethodBearer = "bearer"
+	// MethodJWT is a bearer JWT verified by a TokenValidator.
+	MethodJWT = "jwt"
+	// MethodIntrospection is a bearer token validated by RFC 7662 introspection.
+	MethodIntrospection = "introspection"
+	// MethodAPIKey is an API key resolved by APIKeyAuthenticator.
+	MethodAPIKey = "apiKey"
+	// MethodBasic is a username and password checked by BasicAuthenticator.
+	MethodBasic = "basic"
+	// MethodMutualTLS is a client certificate checked by MutualTLSAuthenticator.
+	MethodMutualTLS = "mtls"
+)
+
+const principalKey tokenKeyType = "a2a-auth-principal"
+
+// Principal is the authenticated caller, set by whichever authenticator succeeded.
+type Principal struct {
+	Subject string
+	Tenant  string
+	Scopes  []string
+	Roles   []string
+	// Audience lists the aud values of a token.
+	Audience []string
+	Claims   map[string]interface{}
+	// Method names the authenticator that produced the principal.
+	Method string
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, candidate := range p.Scopes {
+		if candidate == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the principal holds role.
+func (p *Principal) HasRole(role string) bool {
+	for _, candidate := range p.Roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+// WithPrincipal attaches the authenticated principal to context.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFromContext extracts the authenticated principal if present.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey).(*Principal)
+	return p, ok && p != nil
+}
+
+// PrincipalFromClaims maps token claims to a Principal: sub, tenant (or tid),
+// scopes from the space-delimited scope claim or the scp array, roles and aud.
+func PrincipalFromClaims(claims jwt.Claims, method string) *Principal {
+	p := &Principal{Subject: claims.String("sub"), Claims: claims, Method: method}
+	p.Tenant = claims.String("tenant")
+	if p.Tenant == "" {
+		p.Tenant = claims.String("tid")
+	}
+	p.Scopes = strings.Fields(claims.String("scope"))
+	p.Scopes = append(p.Scopes, stringList(claims["scp"])...)
+	p.Roles = stringList(claims["roles"])
+	if audience := claims.String("aud"); audience != "" {
+		p.Audience = []string{audience}
+	} else {
+		p.Audience = stringList(claims["aud"])
+	}
+	return p
+}
+
+func stringList(v interface{}) []string {
+	values, _ := v.([]interface{})
+	var out []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Access modes of a Rule.
+const (
+	// AccessRequired rejects requests without valid credentials (default).
+	AccessRequired = "required"
+	// AccessOptional authenticates presented credentials but admits anonymous requests.
+	AccessOptional = "optional"
+	// AccessAnonymous skips authentication.
+	AccessAnonymous = "anonymous"
+)
+
+// Rule decides how requests matching Method and Path are authenticated.
+type Rule struct {
+	// Name identifies the rule in decisions; defaults to "Method Path".
+	Name string
+	// Method is the HTTP method; empty matches any.
+	Method string
+	// Path matches exactly or, when ending with "*", by prefix.
+	Path string
+	// Access is AccessRequired when empty.
+	Access string
+	// Authenticators lists accepted authenticator kinds (KindBearer, KindAPIKey,
+	// KindBasic, KindMutualTLS) tried in order. When empty, the card's security
+	// requirements apply if Service.Card is set; otherwise a bearer token or any
+	// registered authenticator is accepted. Scopes and roles required on the
+	// route belong in Policy.Scopes.
+	Authenticators []string
+}
+
+// Matches reports whether the rule applies to r.
+func (rule *Rule) Matches(r *http.Request) bool {
+	return matchRoute(rule.Method, rule.Path, r)
+}
+
+func (rule *Rule) name() string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return strings.TrimSpace(rule.Method + " " + rule.Path)
+}
+
+// DefaultRules leave CORS preflight, the agent card, the JWKS and the
+// protected resource metadata open. They are evaluated after Policy.Rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "preflight", Method: http.MethodOptions, Path: "*", Access: AccessAnonymous},
+		{Name: "resource-metadata", Path: MetadataPath + "*", Access: AccessAnonymous},
+		{Name: "agent-card", Path: "/.well-known/agent-card.json", Access: AccessAnonymous},
+		{Name: "jwks", Path: "/.well-known/jwks.json", Access: AccessAnonymous},
+	}
+}
+
+// Decision records how the middleware ruled on a request.
+type Decision struct {
+	Method  string
+	Path    string
+	Rule    string
+	Allowed bool
+	// Status is the HTTP status of a denial.
+	Status  int
+	Reason  string
+	Subject string
+	// DryRun is set when a denial was logged but not enforced.
+	DryRun bool
+}
+
+// rule returns the first rule matching r: Policy.Rules, then ExcludePrefix,
+// then DefaultRules; unmatched requests require authentication.
+func (s *Service) rule(r *http.Request) Rule {
+	var rules []Rule
+	if s.Policy != nil {
+		rules = append(rules, s.Policy.Rules...)
+		if s.Policy.ExcludePrefix != "" {
+			rules = append(rules, Rule{Name: "exclude-prefix", Path: s.Policy.ExcludePrefix + "*", Access: AccessAnonymous})
+		}
+	}
+	rules = append(rules, DefaultRules()...)
+	for i := range rules {
+		if rules[i].Matches(r) {
+			return rules[i]
+		}
+	}
+	return Rule{Name: "default", Access: AccessRequired}
+}
+
+// record reports the decision to Policy.OnDecision, logging it to
+// Service.Logger in dry-run mode when no callback is set.
+func (s *Service) record(r *http.Request, rule Rule, authenticated *http.Request, denied *denial) {
+	if s.Policy == nil || (s.Policy.OnDecision == nil && !s.Policy.DryRun) {
+		return
+	}
+	decision := Decision{Method: r.Method, Path: r.URL.Path, Rule: rule.name(), Allowed: denied == nil, DryRun: denied != nil && s.Policy.DryRun}
+	if denied != nil {
+		decision.Status, decision.Reason = denied.status, denied.reason
+	}
+	if principal, ok := PrincipalFromContext(authenticated.Context()); ok {
+		decision.Subject = principal.Subject
+	}
+	if s.Policy.OnDecision != nil {
+		s.Policy.OnDecision(decision)
+		return
+	}
+	s.logger().Printf("auth: %s %s rule=%s allowed=%v status=%d reason=%q subject=%q dryRun=%v",
+		decision.Method, decision.Path, decision.Rule, decision.Allowed, decision.Status, decision.Reason, decision.Subject, decision.DryRun)
+}
+
+func matchRoute(method, pattern string, r *http.Request) bool {
+	if method != "" && !strings.EqualFold(method, r.Method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}
+	return r.URL.Path == pattern
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Requirement lists the scopes and roles a principal must all hold.
+type Requirement struct {
+	Scopes []string `json:"scopes,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// IsZero reports whether the requirement demands nothing.
+func (r Requirement) IsZero() bool { return len(r.Scopes) == 0 && len(r.Roles) == 0 }
+
+// Satisfied reports whether p holds every required scope and role. A nil
+// principal satisfies only the zero requirement.
+func (r Requirement) Satisfied(p *Principal) bool {
+	if r.IsZero() {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	for _, scope := range r.Scopes {
+		if !p.HasScope(scope) {
+			return false
+		}
+	}
+	for _, role := range r.Roles {
+		if !p.HasRole(role) {
+			return false
+		}
+	}
+	return true
+}
+
+// RouteRequirement protects HTTP requests matching Method and Path.
+type RouteRequirement struct {
+	// Method is the HTTP method; empty matches any.
+	Method string
+	// Path matches exactly or, when ending with "*", by prefix.
+	Path string
+	Requirement
+}
+
+// Matches reports whether the route applies to r.
+func (rr *RouteRequirement) Matches(r *http.Request) bool {
+	return matchRoute(rr.Method, rr.Path, r)
+}
+
+// ScopePolicy maps A2A methods, REST routes and skills to required scopes or roles.
+// Methods and Skills are enforced by the server (see server.WithScopePolicy);
+// Routes by Middleware.
+type ScopePolicy struct {
+	// Methods maps JSON-RPC method names, e.g. "tasks/cancel".
+	Methods map[string]Requirement
+	// Routes are evaluated in order; the first match applies.
+	Routes []RouteRequirement
+	// Skills maps AgentSkill.id; requirements declared in AgentSkill.security apply as well.
+	Skills map[string]Requirement
+}
+
+// Middleware enforces Routes for the principal set by an earlier
+// authentication middleware, answering 403 insufficient_scope on failure.
+// Service.Middleware enforces them itself when set as Policy.Scopes.
+func (p *ScopePolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := PrincipalFromContext(r.Context())
+		if route := p.route(r); route != nil && !route.Satisfied(principal) {
+			WriteInsufficientScope(w, route.Scopes)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// route returns the first of Routes matching r, or nil.
+func (p *ScopePolicy) route(r *http.Request) *RouteRequirement {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Routes {
+		if p.Routes[i].Matches(r) {
+			return &p.Routes[i]
+		}
+	}
+	return nil
+}
+
+// InsufficientScopeChallenge returns the RFC 6750 WWW-Authenticate value for
+// a request lacking scopes.
+func InsufficientScopeChallenge(scopes []string) string {
+	challenge := `Bearer error="insufficient_scope"`
+	if len(scopes) > 0 {
+		challenge += `, scope="` + strings.Join(scopes, " ") + `"`
+	}
+	return challenge
+}
+
+// WriteInsufficientScope answers 403 with an insufficient_scope challenge.
+func WriteInsufficientScope(w http.ResponseWriter, scopes []string) {
+	w.Header().Set("WWW-Authenticate", InsufficientScopeChallenge(scopes))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write([]byte(`{"error":"insufficient_scope"}`))
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures HTTPS serving with client certificate authentication.
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle of CAs trusted to issue client certificates;
+	// ClientCAs, when set, is used instead.
+	ClientCAFile string
+	ClientCAs    *x509.CertPool
+	// RequireClientCert rejects handshakes without a verified client
+	// certificate; otherwise one is verified only when presented, so other
+	// authenticators can serve the remaining callers.
+	RequireClientCert bool
+}
+
+// Config builds a server tls.Config; use it with http.Server.TLSConfig and
+// ListenAndServeTLS("", "").
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("server certificate: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	pool := o.ClientCAs
+	if pool == nil && o.ClientCAFile != "" {
+		if pool, err = LoadCertPool(o.ClientCAFile); err != nil {
+			return nil, err
+		}
+	}
+	if pool == nil {
+		return config, nil
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if o.RequireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// SPIFFEID returns the spiffe:// URI SAN of cert, if any.
+func SPIFFEID(cert *x509.Certificate) string {
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			return uri.String()
+		}
+	}
+	return ""
+}
+
+// PrincipalFromCertificate maps a verified client certificate to a Principal.
+// The subject is the SPIFFE ID when present, otherwise the first URI SAN,
+// otherwise the common name. Certificate details are kept in Claims.
+func PrincipalFromCertificate(cert *x509.Certificate) *Principal {
+	fingerprint := sha256.Sum256(cert.Raw)
+	claims := map[string]interface{}{
+		"subject_dn":         cert.Subject.String(),
+		"serial":             cert.SerialNumber.String(),
+		"fingerprint_sha256": hex.EncodeToString(fingerprint[:]),
+	}
+	p := &Principal{Subject: cert.Subject.CommonName, Claims: claims, Method: MethodMutualTLS}
+	var uris []string
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+	if len(uris) > 0 {
+		claims["uris"] = uris
+		p.Subject = uris[0]
+	}
+	if len(cert.DNSNames) > 0 {
+		claims["dns_names"] = cert.DNSNames
+	}
+	if id := SPIFFEID(cert); id != "" {
+		p.Subject = id
+		claims["spiffe_id"] = id
+		claims["spiffe_trust_domain"] = spiffeTrustDomain(id)
+	}
+	if len(cert.Subject.Organization) > 0 {
+		p.Tenant = cert.Subject.Organization[0]
+	}
+	return p
+}
+
+func spiffeTrustDomain(id string) string {
+	domain, _, _ := strings.Cut(strings.TrimPrefix(id, "spiffe://"), "/")
+	return domain
+}
+
+// errUntrustedDomain is returned for a certificate outside the allowed SPIFFE trust domains.
+var errUntrustedDomain = errors.New("spiffe trust domain not allowed")
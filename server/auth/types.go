@@ -1,21 +1,44 @@
 package auth
 
-// ProtectedResourceMetadata describes the OAuth/OIDC metadata for a protected resource.
-// This is served at /.well-known/oauth-protected-resource.
-type ProtectedResourceMetadata struct {
-	Resource         string   `json:"resource"`
-	Issuer           string   `json:"issuer,omitempty"`
-	AuthorizationURI string   `json:"authorization_uri,omitempty"`
-	TokenEndpoint    string   `json:"token_endpoint,omitempty"`
-	ScopesSupported  []string `json:"scopes_supported,omitempty"`
-}
+import (
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+)
+
+// ProtectedResourceMetadata is the OAuth 2.0 protected resource metadata
+// document (RFC 9728) served at MetadataPath.
+type ProtectedResourceMetadata = schema.ProtectedResourceMetadata
 
 // Policy controls which endpoints are protected and metadata returned to clients.
 type Policy struct {
 	// If set, requests with path that has this prefix are bypassed (no auth).
+	//
+	// Deprecated: use an AccessAnonymous rule in Rules.
 	ExcludePrefix string
+	// Rules are evaluated in order before DefaultRules; the first match decides
+	// how a request is authenticated.
+	Rules []Rule
+	// DryRun logs denials without enforcing them.
+	DryRun bool
+	// OnDecision, when set, receives every decision instead of the dry-run log.
+	OnDecision func(Decision)
+	// Scopes, when set, has its Routes enforced by the middleware once the
+	// matching rule admitted the request. Denials are recorded and subject to
+	// DryRun like those of rules.
+	Scopes *ScopePolicy
 	// Metadata served for the resource.
 	Metadata *ProtectedResourceMetadata
+	// Resources describes further protected resources on the same host, keyed
+	// by path prefix (e.g. "/tenants/acme"). Their metadata is served at the
+	// path-suffixed URL /.well-known/oauth-protected-resource/tenants/acme.
+	Resources map[string]*ProtectedResourceMetadata
+	// MetadataKeys, when set, signs the served metadata into signed_metadata.
+	// Signed metadata must set Resource.
+	MetadataKeys *jwt.KeySet
+	// TrustedProxies lists the IP addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-Proto and X-Forwarded-Host headers name the public
+	// origin in metadata and challenges. Other peers' headers are ignored.
+	TrustedProxies []string
 	// If true, prefer ID token from source; otherwise use access token when acquiring.
 	UseIDToken bool
 }
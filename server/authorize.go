@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+// codeInsufficientScope is returned when the caller lacks a required scope or role.
+const codeInsufficientScope = -32010
+
+// WithScopePolicy enforces policy.Methods and policy.Skills on every A2A
+// method and transport. Skill requirements declared in AgentSkill.security are
+// read from the current card. The check runs innermost, after interceptors
+// registered with WithInterceptors. Enforce policy.Routes with policy.Middleware.
+func WithScopePolicy(policy *auth.ScopePolicy) ServerOption {
+	return func(s *Server) { s.scopePolicy = policy }
+}
+
+// authorize is the interceptor installed by WithScopePolicy.
+func (s *Server) authorize(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	if requirement, ok := s.scopePolicy.Methods[method]; ok && !requirement.Satisfied(principal) {
+		response.Error = insufficientScope(requirement.Scopes)
+		return
+	}
+	if method == "message/send" || method == "message/stream" {
+		skillID := requestedSkill(request.Params)
+		if skillID == "" && s.skillsRestricted() {
+			// without a skill ID no skill requirement could be checked
+			response.Error = jsonrpc.NewInvalidParamsError("metadata.skillId required: skills declare access requirements", request.Params)
+			return
+		}
+		if skillID != "" {
+			if err := s.authorizeSkill(principal, skillID, request.Params); err != nil {
+				response.Error = err
+				return
+			}
+		}
+	}
+	next(ctx, request, response)
+}
+
+// authorizeSkill checks the policy and AgentSkill.security requirements of skillID.
+func (s *Server) authorizeSkill(principal *auth.Principal, skillID string, params []byte) *jsonrpc.Error {
+	if requirement, ok := s.scopePolicy.Skills[skillID]; ok && !requirement.Satisfied(principal) {
+		return insufficientScope(requirement.Scopes)
+	}
+	card := s.Card()
+	for _, skill := range card.Skills {
+		if skill.ID != skillID {
+			continue
+		}
+		if ok, scopes := securitySatisfied(principal, skill.Security); !ok {
+			return insufficientScope(scopes)
+		}
+		return nil
+	}
+	if len(card.Skills) > 0 {
+		return jsonrpc.NewInvalidParamsError("unknown skill: "+skillID, params)
+	}
+	return nil
+}
+
+// skillsRestricted reports whether the policy or the card declares a
+// requirement for any skill.
+func (s *Server) skillsRestricted() bool {
+	for _, requirement := range s.scopePolicy.Skills {
+		if !requirement.IsZero() {
+			return true
+		}
+	}
+	for _, skill := range s.Card().Skills {
+		if len(skill.Security) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// securitySatisfied evaluates an OR of ANDs of scheme scopes. On failure it
+// returns the scopes of the first alternative for the challenge.
+func securitySatisfied(principal *auth.Principal, security []map[string][]string) (bool, []string) {
+	if len(security) == 0 {
+		return true, nil
+	}
+	var first []string
+	for i, alternative := range security {
+		var required []string
+		for _, scopes := range alternative {
+			required = append(required, scopes...)
+		}
+		if (auth.Requirement{Scopes: required}).Satisfied(principal) && principal != nil {
+			return true, nil
+		}
+		if i == 0 {
+			first = required
+		}
+	}
+	return false, first
+}
+
+// requestedSkill returns params.metadata.skillId of a message request.
+func requestedSkill(params []byte) string {
+	var p struct {
+		Metadata struct {
+			SkillID string `json:"skillId"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return ""
+	}
+	return p.Metadata.SkillID
+}
+
+// scopeErrorData is the data member of an insufficient-scope error.
+type scopeErrorData struct {
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+func insufficientScope(scopes []string) *jsonrpc.Error {
+	message := "insufficient scope"
+	if len(scopes) > 0 {
+		message += ": requires " + strings.Join(scopes, " ")
+	}
+	return jsonrpc.NewError(codeInsufficientScope, message, &scopeErrorData{Scopes: scopes})
+}
+
+// challengeInsufficientScope sets the RFC 6750 challenge for an
+// insufficient-scope error answered over plain HTTP, reporting whether the
+// response should use 403.
+func challengeInsufficientScope(w http.ResponseWriter, rpcErr *jsonrpc.Error) bool {
+	if rpcErr == nil || rpcErr.Code != codeInsufficientScope {
+		return false
+	}
+	data := &scopeErrorData{}
+	_ = json.Unmarshal(rpcErr.Data, data)
+	w.Header().Set("WWW-Authenticate", auth.InsufficientScopeChallenge(data.Scopes))
+	return true
+}
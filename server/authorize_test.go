@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+)
+
+func TestScopePolicy(t *testing.T) {
+	card := schema.AgentCard{Name: "test", Skills: []schema.AgentSkill{
+		{ID: "echo"},
+		{ID: "report", Security: []map[string][]string{{"oauth": {"reports.read"}}, {"oauth": {"admin"}}}},
+	}}
+	policy := &auth.ScopePolicy{
+		Methods: map[string]auth.Requirement{"tasks/cancel": {Scopes: []string{"tasks.write"}}},
+		Skills:  map[string]auth.Requirement{"echo": {Roles: []string{"operator"}}},
+	}
+	srv := New(card, WithScopePolicy(policy))
+	mux := http.NewServeMux()
+	srv.RegisterJSONRPC(mux, "/rpc")
+	srv.RegisterREST(mux)
+	var principal *auth.Principal
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}))
+	defer ts.Close()
+
+	send := func(skill string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"messages":[{"role":"user","parts":[{"type":"text","text":"hi"}]}],"metadata":{"skillId":"` + skill + `"}}}`
+	}
+	testCases := []struct {
+		description string
+		principal   *auth.Principal
+		body        string
+		expect      int
+		challenge   string
+		code        string
+	}{
+		{description: "method without scope", principal: &auth.Principal{Subject: "a"}, body: `{"jsonrpc":"2.0","id":1,"method":"tasks/cancel","params":{"id":"t-1"}}`, expect: http.StatusForbidden, challenge: `scope="tasks.write"`, code: `"data":{"scopes":["tasks.write"]}`},
+		{description: "method with scope", principal: &auth.Principal{Subject: "a", Scopes: []string{"tasks.write"}}, body: `{"jsonrpc":"2.0","id":1,"method":"tasks/cancel","params":{"id":"t-1"}}`, expect: http.StatusOK},
+		{description: "unrestricted method", body: `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"t-1"}}`, expect: http.StatusOK},
+		{description: "policy skill role missing", principal: &auth.Principal{Subject: "a"}, body: send("echo"), expect: http.StatusForbidden},
+		{description: "policy skill role held", principal: &auth.Principal{Subject: "a", Roles: []string{"operator"}}, body: send("echo"), expect: http.StatusOK},
+		{description: "card skill security unmet", principal: &auth.Principal{Subject: "a"}, body: send("report"), expect: http.StatusForbidden, challenge: `scope="reports.read"`, code: `"data":{"scopes":["reports.read"]}`},
+		{description: "card skill security alternative", principal: &auth.Principal{Subject: "a", Scopes: []string{"admin"}}, body: send("report"), expect: http.StatusOK},
+		{description: "unknown skill", principal: &auth.Principal{Subject: "a"}, body: send("missing"), expect: http.StatusOK},
+		{description: "skill omitted", principal: &auth.Principal{Subject: "a"}, body: `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"messages":[{"role":"user","parts":[{"type":"text","text":"hi"}]}]}}`, expect: http.StatusOK, code: "-32602"},
+	}
+	for _, testCase := range testCases {
+		principal = testCase.principal
+		resp, body := postRaw(t, ts, testCase.body)
+		if resp.StatusCode != testCase.expect {
+			t.Errorf("%s: status=%d want %d body=%s", testCase.description, resp.StatusCode, testCase.expect, body)
+			continue
+		}
+		if testCase.expect == http.StatusForbidden && !strings.Contains(string(body), "-32010") {
+			t.Errorf("%s: body=%s", testCase.description, body)
+		}
+		if !strings.Contains(string(body), testCase.code) {
+			t.Errorf("%s: body=%s", testCase.description, body)
+		}
+		if !strings.Contains(resp.Header.Get("WWW-Authenticate"), testCase.challenge) {
+			t.Errorf("%s: challenge=%q", testCase.description, resp.Header.Get("WWW-Authenticate"))
+		}
+	}
+
+	principal = &auth.Principal{Subject: "a"}
+	resp, err := http.Post(ts.URL+"/v1/tasks/t-1:cancel", "application/json", nil)
+	if err != nil {
+		t.Fatalf("rest cancel: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope") {
+		t.Fatalf("rest status=%d challenge=%q", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+)
+
+// CardProviderFunc returns the current public agent card.
+type CardProviderFunc func() schema.AgentCard
+
+// ExtendedCardFunc returns the authenticated extended agent card for the caller in ctx.
+type ExtendedCardFunc func(ctx context.Context) (*schema.AgentCard, error)
+
+// errExtendedCardNotConfigured maps to AuthenticatedExtendedCardNotConfiguredError (-32007).
+var errExtendedCardNotConfigured = errors.New("Authenticated Extended Card not configured")
+
+// codeUnauthenticated is returned when a method requires an authenticated
+// caller; REST answers it with 401.
+const codeUnauthenticated = -32012
+
+// errUnauthenticated rejects anonymous requests for the extended card.
+var errUnauthenticated = errors.New("authentication required")
+
+// Card returns a snapshot of the current public agent card. It is safe for
+// concurrent use with UpdateCard.
+func (s *Server) Card() schema.AgentCard {
+	s.cardMu.RLock()
+	card, provider, hasExtended := s.card, s.cardProvider, s.extendedCard != nil
+	s.cardMu.RUnlock()
+	if provider != nil {
+		card = provider()
+	}
+	if hasExtended {
+		card.SupportsAuthenticatedExtendedCard = true
+	}
+	return card
+}
+
+// UpdateCard atomically replaces the public agent card (and any configured
+// card provider). Capability gating and card endpoints observe the new card
+// on the next request; open streams are unaffected.
+func (s *Server) UpdateCard(card schema.AgentCard) {
+	s.cardMu.Lock()
+	defer s.cardMu.Unlock()
+	s.card = card
+	s.cardProvider = nil
+}
+
+// UpdateExtendedCard atomically replaces the authenticated extended agent card.
+func (s *Server) UpdateExtendedCard(card schema.AgentCard) {
+	s.cardMu.Lock()
+	defer s.cardMu.Unlock()
+	s.extendedCard = staticCard(card)
+}
+
+func (s *Server) streamingSupported() bool {
+	card := s.Card()
+	return card.StreamingSupported()
+}
+
+func (s *Server) pushSupported() bool {
+	card := s.Card()
+	return card.PushNotificationsSupported()
+}
+
+func staticCard(card schema.AgentCard) ExtendedCardFunc {
+	return func(context.Context) (*schema.AgentCard, error) {
+		c := card
+		return &c, nil
+	}
+}
+
+// extendedCardFor resolves the extended card for the caller in ctx. Anonymous
+// callers get errUnauthenticated, even when no auth middleware is mounted.
+func (s *Server) extendedCardFor(ctx context.Context) (*schema.AgentCard, error) {
+	s.cardMu.RLock()
+	provider := s.extendedCard
+	s.cardMu.RUnlock()
+	if provider == nil {
+		return nil, errExtendedCardNotConfigured
+	}
+	if _, ok := auth.PrincipalFromContext(ctx); !ok {
+		return nil, errUnauthenticated
+	}
+	card, err := provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, errExtendedCardNotConfigured
+	}
+	// providers may return a shared card, so flag a copy
+	extended := *card
+	extended.SupportsAuthenticatedExtendedCard = true
+	return &extended, nil
+}
+
+// GET /v1/card
+func (s *Server) handleExtendedCardREST(w http.ResponseWriter, r *http.Request) {
+	response := s.callREST(r, "agent/getAuthenticatedExtendedCard", nil)
+	var card schema.AgentCard
+	if response.Error != nil || json.Unmarshal(response.Result, &card) != nil {
+		writeRESTResult(w, response, http.StatusOK)
+		return
+	}
+	w.Header().Set("Vary", "Authorization")
+	serveCard(w, r, &card, "private, no-cache")
+}
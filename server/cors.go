@@ -0,0 +1,136 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default CORS headers, used when the corresponding CORS field is empty.
+var (
+	DefaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions}
+	DefaultCORSHeaders = []string{"Authorization", "Content-Type", "Accept", "Last-Event-ID", "Mcp-Session-Id", "X-API-Key", "If-None-Match"}
+	DefaultCORSExposed = []string{"Mcp-Session-Id", "WWW-Authenticate", "Retry-After", "ETag"}
+)
+
+// CORS configures cross-origin access for browser-based clients.
+type CORS struct {
+	// AllowedOrigins lists origins such as "https://console.example.com".
+	// "*" allows any origin and "https://*.example.com" any subdomain.
+	AllowedOrigins []string
+	// AllowedMethods defaults to DefaultCORSMethods.
+	AllowedMethods []string
+	// AllowedHeaders defaults to DefaultCORSHeaders.
+	AllowedHeaders []string
+	// ExposedHeaders defaults to DefaultCORSExposed.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and TLS client certificates
+	// from listed origins, whose value is echoed instead of "*". Origins
+	// admitted only by "*" never get credentialed access.
+	AllowCredentials bool
+	// MaxAge lets browsers cache preflight results.
+	MaxAge time.Duration
+}
+
+// WithCORS answers preflight requests and sets Access-Control-* headers on
+// every route mounted by the Register helpers, streams included.
+func WithCORS(cors CORS) ServerOption {
+	return func(s *Server) {
+		if len(cors.AllowedMethods) == 0 {
+			cors.AllowedMethods = DefaultCORSMethods
+		}
+		if len(cors.AllowedHeaders) == 0 {
+			cors.AllowedHeaders = DefaultCORSHeaders
+		}
+		if len(cors.ExposedHeaders) == 0 {
+			cors.ExposedHeaders = DefaultCORSExposed
+		}
+		s.cors = &cors
+	}
+}
+
+// CORS wraps next with the server's CORS handling. Use it around
+// middleware, such as auth.Service.Middleware, whose own responses (a 401
+// challenge, say) browsers should be able to read. Without WithCORS it
+// returns next unchanged.
+func (s *Server) CORS(next http.Handler) http.Handler {
+	if s.cors == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cors.apply(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handle mounts h on mux at path with the server's CORS handling.
+func (s *Server) handle(mux *http.ServeMux, path string, h http.Handler) {
+	mux.Handle(path, s.CORS(h))
+}
+
+// apply sets the CORS headers for r and reports whether r was a preflight
+// request it answered. Headers are set, not added, so nested handlers agree.
+func (c *CORS) apply(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	header := w.Header()
+	// responses differ by origin, so caches must key on it even when the
+	// request carried none
+	if !strings.Contains(strings.Join(header.Values("Vary"), ","), "Origin") {
+		header.Add("Vary", "Origin")
+	}
+	listed := origin != "" && c.lists(origin)
+	if origin == "" || !(listed || c.allowsAny()) {
+		if preflight {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return preflight
+	}
+	if listed {
+		header.Set("Access-Control-Allow-Origin", origin)
+		if c.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	} else {
+		header.Set("Access-Control-Allow-Origin", "*")
+	}
+	if !preflight {
+		header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		return false
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+	header.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+	if c.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (c *CORS) allowsAny() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// lists matches origin exactly or by a "scheme://*.domain" pattern.
+func (c *CORS) lists(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			host := strings.TrimPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://")
+			if host != strings.ToLower(origin) && strings.HasSuffix(host, "."+strings.ToLower(domain)) {
+				return true
+			}
+		}
+	}
+	return false
+}
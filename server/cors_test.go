@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema"
+)
+
+func TestCORS(t *testing.T) {
+	srv := New(schema.AgentCard{Name: "test"}, WithCORS(CORS{
+		AllowedOrigins:   []string{"https://console.example.com", "https://*.agents.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+	mux := http.NewServeMux()
+	srv.RegisterJSONRPC(mux, "/rpc")
+	srv.RegisterREST(mux)
+	srv.RegisterSSE(mux, "/sse")
+	srv.RegisterStreaming(mux, "/a2a")
+	srv.RegisterWellKnown(mux)
+	// a browser can read the 401 of middleware wrapped by srv.CORS
+	outer := http.NewServeMux()
+	outer.Handle("/", srv.CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions && r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})))
+	ts := httptest.NewServer(outer)
+	defer ts.Close()
+
+	testCases := []struct {
+		description   string
+		method        string
+		path          string
+		origin        string
+		preflight     bool
+		authorized    bool
+		expectStatus  int
+		expectOrigin  string
+		expectHeaders string
+	}{
+		{description: "json-rpc preflight", method: http.MethodOptions, path: "/rpc", origin: "https://console.example.com", preflight: true, expectStatus: http.StatusNoContent, expectOrigin: "https://console.example.com", expectHeaders: "Mcp-Session-Id"},
+		{description: "streamable preflight", method: http.MethodOptions, path: "/a2a", origin: "https://console.example.com", preflight: true, expectStatus: http.StatusNoContent, expectOrigin: "https://console.example.com", expectHeaders: "Authorization"},
+		{description: "subdomain origin", method: http.MethodOptions, path: "/v1/tasks", origin: "https://eu.agents.example.com", preflight: true, expectStatus: http.StatusNoContent, expectOrigin: "https://eu.agents.example.com"},
+		{description: "disallowed origin", method: http.MethodOptions, path: "/rpc", origin: "https://evil.example.org", preflight: true, expectStatus: http.StatusNoContent},
+		{description: "lookalike origin", method: http.MethodOptions, path: "/rpc", origin: "https://agents.example.com.evil.org", preflight: true, expectStatus: http.StatusNoContent},
+		{description: "rest response", method: http.MethodGet, path: "/v1/tasks", origin: "https://console.example.com", authorized: true, expectStatus: http.StatusOK, expectOrigin: "https://console.example.com"},
+		{description: "agent card", method: http.MethodGet, path: WellKnownAgentCardPath, origin: "https://console.example.com", authorized: true, expectStatus: http.StatusOK, expectOrigin: "https://console.example.com"},
+		{description: "unauthorized response", method: http.MethodGet, path: "/v1/tasks", origin: "https://console.example.com", expectStatus: http.StatusUnauthorized, expectOrigin: "https://console.example.com"},
+		{description: "same origin", method: http.MethodGet, path: "/v1/tasks", authorized: true, expectStatus: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		req, _ := http.NewRequest(testCase.method, ts.URL+testCase.path, nil)
+		if testCase.origin != "" {
+			req.Header.Set("Origin", testCase.origin)
+		}
+		if testCase.preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+		}
+		if testCase.authorized {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.description, err)
+		}
+		resp.Body.Close()
+		header := resp.Header
+		if strings.Count(strings.Join(header.Values("Vary"), ","), "Origin") != 1 {
+			t.Fatalf("%s: vary=%v", testCase.description, header.Values("Vary"))
+		}
+		if resp.StatusCode != testCase.expectStatus || header.Get("Access-Control-Allow-Origin") != testCase.expectOrigin {
+			t.Fatalf("%s: status=%d origin=%q", testCase.description, resp.StatusCode, header.Get("Access-Control-Allow-Origin"))
+		}
+		if testCase.expectOrigin == "" {
+			if header.Get("Access-Control-Allow-Credentials") != "" || header.Get("Access-Control-Allow-Methods") != "" {
+				t.Fatalf("%s: unexpected CORS headers %v", testCase.description, header)
+			}
+			continue
+		}
+		if header.Get("Access-Control-Allow-Credentials") != "true" {
+			t.Fatalf("%s: headers=%v", testCase.description, header)
+		}
+		if testCase.preflight {
+			if !strings.Contains(header.Get("Access-Control-Allow-Headers"), testCase.expectHeaders) || header.Get("Access-Control-Max-Age") != "600" {
+				t.Fatalf("%s: headers=%v", testCase.description, header)
+			}
+			continue
+		}
+		if !strings.Contains(header.Get("Access-Control-Expose-Headers"), "WWW-Authenticate") {
+			t.Fatalf("%s: headers=%v", testCase.description, header)
+		}
+	}
+
+	// stream responses carry the headers before the first event is flushed
+	streams := httptest.NewServer(srv.CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+	})))
+	defer streams.Close()
+	req, _ := http.NewRequest(http.MethodGet, streams.URL, nil)
+	req.Header.Set("Origin", "https://console.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Access-Control-Allow-Origin") != "https://console.example.com" {
+		t.Fatalf("stream headers=%v", resp.Header)
+	}
+
+	// "*" never grants credentialed access, only listed origins do
+	open := New(schema.AgentCard{Name: "test"}, WithCORS(CORS{AllowedOrigins: []string{"*", "https://console.example.com"}, AllowCredentials: true}))
+	wildcard := httptest.NewServer(open.CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer wildcard.Close()
+	for origin, credentials := range map[string]string{"https://evil.example.org": "", "https://console.example.com": "true"} {
+		req, _ = http.NewRequest(http.MethodGet, wildcard.URL, nil)
+		req.Header.Set("Origin", origin)
+		if resp, err = http.DefaultClient.Do(req); err != nil {
+			t.Fatalf("wildcard: %v", err)
+		}
+		resp.Body.Close()
+		expectOrigin := origin
+		if credentials == "" {
+			expectOrigin = "*"
+		}
+		if resp.Header.Get("Access-Control-Allow-Origin") != expectOrigin || resp.Header.Get("Access-Control-Allow-Credentials") != credentials {
+			t.Fatalf("wildcard %s: headers=%v", origin, resp.Header)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/jsonrpc"
+)
+
+// CredentialBroker mediates credentials an executor needs from the client in
+// the middle of a task, i.e. the auth-required state.
+type CredentialBroker interface {
+	// Request records that taskID waits for req and returns the structured
+	// status message describing it.
+	Request(ctx context.Context, taskID string, req schema.CredentialRequest) *schema.DataPart
+	// Supply stores the credentials carried by messages continuing taskID and
+	// returns how many were found.
+	Supply(ctx context.Context, taskID string, messages []schema.Message) (int, error)
+	// Credential returns the credential supplied to taskID for resource.
+	Credential(ctx context.Context, taskID, resource string) (*schema.Credential, bool)
+	// Wait blocks until a credential for resource is supplied to taskID or ctx ends.
+	Wait(ctx context.Context, taskID, resource string) (*schema.Credential, error)
+	// Release forgets the credentials and requests of taskID.
+	Release(taskID string)
+}
+
+// WithCredentialBroker replaces the default in-memory credential broker.
+func WithCredentialBroker(broker CredentialBroker) ServerOption {
+	return func(s *Server) { s.credentials = broker }
+}
+
+// Credentials returns the server's credential broker.
+func (s *Server) Credentials() CredentialBroker { return s.credentials }
+
+// RequestCredential moves task to auth-required with a status message
+// describing req. Executors then resume once Credentials().Wait returns or
+// when the client continues the task with the credential.
+func (s *Server) RequestCredential(ctx context.Context, task *schema.Task, req schema.CredentialRequest) {
+	message := s.credentials.Request(ctx, task.ID, req)
+	task.Touch(schema.TaskAuthRequired)
+	task.Status.Message = message
+	s.tasks.put(task)
+}
+
+// supplyCredentials hands credentials in messages to the broker, moving an
+// auth-required task back to running when any were supplied.
+func (s *Server) supplyCredentials(ctx context.Context, task *schema.Task, messages []schema.Message) error {
+	n, err := s.credentials.Supply(ctx, task.ID, messages)
+	if err != nil || n == 0 {
+		return err
+	}
+	if task.Status.State == schema.TaskAuthRequired {
+		task.Touch(schema.TaskRunning)
+		task.Status.Message = nil
+		s.tasks.put(task)
+	}
+	return nil
+}
+
+// continueWithCredentials supplies credentials in messages continuing an
+// existing task, so executors waiting in auth-required resume.
+func (s *Server) continueWithCredentials(ctx context.Context, taskID *string, messages []schema.Message, params []byte) *jsonrpc.Error {
+	if taskID == nil || *taskID == "" {
+		return nil
+	}
+	task, ok := s.tasks.get(*taskID)
+	if !ok {
+		return nil
+	}
+	if err := s.supplyCredentials(ctx, task, messages); err != nil {
+		return jsonrpc.NewInvalidParamsError(err.Error(), params)
+	}
+	return nil
+}
+
+// memoryBroker keeps credentials in memory, sealed with a per-process key.
+type memoryBroker struct {
+	aead    cipher.AEAD
+	mu      sync.Mutex
+	sealed  map[string]map[string][]byte
+	pending map[string]map[string]bool
+	waiters map[string]map[string][]chan struct{}
+}
+
+// NewCredentialBroker returns the default in-memory broker. Credentials are
+// encrypted at rest with AES-GCM under a random key and dropped once the
+// task reaches a terminal state.
+func NewCredentialBroker() CredentialBroker {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	return &memoryBroker{
+		aead:    aead,
+		sealed:  map[string]map[string][]byte{},
+		pending: map[string]map[string]bool{},
+		waiters: map[string]map[string][]chan struct{}{},
+	}
+}
+
+func (b *memoryBroker) Request(_ context.Context, taskID string, req schema.CredentialRequest) *schema.DataPart {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending[taskID] == nil {
+		b.pending[taskID] = map[string]bool{}
+	}
+	b.pending[taskID][req.Resource] = true
+	return req.DataPart()
+}
+
+func (b *memoryBroker) Supply(_ context.Context, taskID string, messages []schema.Message) (int, error) {
+	credentials, err := credentialsFromMessages(messages)
+	if err != nil || len(credentials) == 0 {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, credential := range credentials {
+		if credential.Resource == "" && len(b.pending[taskID]) == 1 {
+			for resource := range b.pending[taskID] {
+				credential.Resource = resource
+			}
+		}
+		plain, _ := json.Marshal(credential)
+		nonce := make([]byte, b.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return 0, err
+		}
+		if b.sealed[taskID] == nil {
+			b.sealed[taskID] = map[string][]byte{}
+		}
+		b.sealed[taskID][credential.Resource] = b.aead.Seal(nonce, nonce, plain, []byte(taskID))
+		delete(b.pending[taskID], credential.Resource)
+		for _, waiter := range b.waiters[taskID][credential.Resource] {
+			close(waiter)
+		}
+		delete(b.waiters[taskID], credential.Resource)
+	}
+	return len(credentials), nil
+}
+
+func (b *memoryBroker) Credential(_ context.Context, taskID, resource string) (*schema.Credential, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open(taskID, resource)
+}
+
+// open decrypts the credential of taskID for resource; callers hold mu.
+func (b *memoryBroker) open(taskID, resource string) (*schema.Credential, bool) {
+	sealed, ok := b.sealed[taskID][resource]
+	if !ok {
+		return nil, false
+	}
+	size := b.aead.NonceSize()
+	plain, err := b.aead.Open(nil, sealed[:size], sealed[size:], []byte(taskID))
+	if err != nil {
+		return nil, false
+	}
+	credential := &schema.Credential{}
+	if err := json.Unmarshal(plain, credential); err != nil {
+		return nil, false
+	}
+	return credential, true
+}
+
+func (b *memoryBroker) Wait(ctx context.Context, taskID, resource string) (*schema.Credential, error) {
+	b.mu.Lock()
+	if credential, ok := b.open(taskID, resource); ok {
+		b.mu.Unlock()
+		return credential, nil
+	}
+	ready := make(chan struct{})
+	if b.waiters[taskID] == nil {
+		b.waiters[taskID] = map[string][]chan struct{}{}
+	}
+	b.waiters[taskID][resource] = append(b.waiters[taskID][resource], ready)
+	b.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-ready:
+	}
+	if credential, ok := b.Credential(ctx, taskID, resource); ok {
+		return credential, nil
+	}
+	return nil, errCredentialReleased
+}
+
+func (b *memoryBroker) Release(taskID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.sealed, taskID)
+	delete(b.pending, taskID)
+	for _, waiters := range b.waiters[taskID] {
+		for _, waiter := range waiters {
+			close(waiter)
+		}
+	}
+	delete(b.waiters, taskID)
+}
+
+var errCredentialReleased = errors.New("task ended before the credential was supplied")
+
+// credentialsFromMessages extracts credentials from data parts under
+// schema.CredentialKey.
+func credentialsFromMessages(messages []schema.Message) ([]schema.Credential, error) {
+	var out []schema.Credential
+	for _, message := range messages {
+		for _, raw := range message.PartsRaw {
+			var part struct {
+				Type string                     `json:"type"`
+				Data map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(raw, &part); err != nil || !strings.EqualFold(part.Type, "data") {
+				continue
+			}
+			if value, ok := part.Data[schema.CredentialKey]; ok {
+				var credential schema.Credential
+				if err := json.Unmarshal(value, &credential); err != nil || strings.TrimSpace(credential.Token) == "" {
+					return nil, errors.New("invalid credential: token required")
+				}
+				out = append(out, credential)
+			}
+		}
+	}
+	return out, nil
+}
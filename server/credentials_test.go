@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/jsonrpc"
+)
+
+func TestCredentialBroker(t *testing.T) {
+	demo, mux := newTestServer(false, false)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// a task awaiting a credential stays auth-required until it is supplied
+	task := demo.tasks.newTask(nil)
+	demo.RequestCredential(context.Background(), task, schema.CredentialRequest{Resource: "crm", Scopes: []string{"read"}})
+	if auth, _ := task.Status.Message.Data[schema.CredentialRequestKey].(map[string]interface{}); task.Status.State != schema.TaskAuthRequired || auth["resource"] != "crm" {
+		t.Fatalf("status=%+v", task.Status)
+	}
+	rpc := rpcCall(t, ts, "message/send", map[string]interface{}{
+		"taskId":   task.ID,
+		"messages": []map[string]interface{}{{"role": "user", "parts": []interface{}{map[string]interface{}{"type": "data", "data": map[string]interface{}{"secondaryAuthToken": "s3cret", "resource": "crm"}}}}},
+	})
+	if rpc.Error != nil || json.Unmarshal(rpc.Result, task) != nil || task.Status.State != schema.TaskAuthRequired {
+		t.Fatalf("legacy key: err=%+v status=%+v", rpc.Error, task.Status)
+	}
+	credential := schema.Credential{Token: "s3cret"}.DataPart()
+	rpc = rpcCall(t, ts, "message/send", map[string]interface{}{
+		"taskId":   task.ID,
+		"messages": []map[string]interface{}{{"role": "user", "parts": []interface{}{credential}}},
+	})
+	if rpc.Error != nil || json.Unmarshal(rpc.Result, task) != nil || task.Status.State != schema.TaskCompleted {
+		t.Fatalf("continue: err=%+v status=%+v", rpc.Error, task.Status)
+	}
+	rpc = rpcCall(t, ts, "message/send", map[string]interface{}{
+		"messages": []map[string]interface{}{{"role": "user", "parts": []interface{}{map[string]interface{}{"type": "data", "data": map[string]interface{}{"credential": map[string]interface{}{"resource": "crm"}}}}}},
+	})
+	if rpc.Error == nil || rpc.Error.Code != -32602 {
+		t.Fatalf("credential without token accepted: %+v", rpc.Error)
+	}
+
+	// an executor waits for the credential and resumes when it arrives
+	resumed := make(chan string, 1)
+	var srv *Server
+	newOps := WithDefaultHandler(context.Background(), RegisterMessageSend(func(ctx context.Context, messages []schema.Message, contextID, taskID *string) (*schema.Task, *jsonrpc.Error) {
+		if taskID != nil {
+			task, _ := srv.tasks.get(*taskID)
+			return task, nil
+		}
+		task := srv.tasks.newTask(contextID)
+		srv.RequestCredential(ctx, task, schema.CredentialRequest{Resource: "calendar", Scheme: "bearer"})
+		go func() {
+			waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			credential, err := srv.Credentials().Wait(waitCtx, task.ID, "calendar")
+			if err != nil {
+				resumed <- err.Error()
+				return
+			}
+			resumed <- credential.Token
+		}()
+		return task, nil
+	}))
+	srv = New(schema.AgentCard{Name: "test"}, WithOperations(newOps))
+	executorMux := http.NewServeMux()
+	srv.RegisterJSONRPC(executorMux, "/rpc")
+	executorTS := httptest.NewServer(executorMux)
+	defer executorTS.Close()
+
+	rpc = rpcCall(t, executorTS, "message/send", map[string]interface{}{
+		"messages": []map[string]interface{}{{"role": "user", "parts": []map[string]interface{}{{"type": "text", "text": "book a meeting"}}}},
+	})
+	if rpc.Error != nil || json.Unmarshal(rpc.Result, &task) != nil || task.Status.State != schema.TaskAuthRequired {
+		t.Fatalf("executor send: err=%+v status=%+v", rpc.Error, task.Status)
+	}
+	rpc = rpcCall(t, executorTS, "message/send", map[string]interface{}{
+		"taskId":   task.ID,
+		"messages": []map[string]interface{}{{"role": "user", "parts": []interface{}{schema.Credential{Token: "cal-token"}.DataPart()}}},
+	})
+	if rpc.Error != nil || json.Unmarshal(rpc.Result, &task) != nil || task.Status.State != schema.TaskRunning {
+		t.Fatalf("executor continue: err=%+v status=%+v", rpc.Error, task.Status)
+	}
+	select {
+	case token := <-resumed:
+		if token != "cal-token" {
+			t.Fatalf("resumed with %q", token)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("executor not resumed")
+	}
+
+	stored, _ := srv.tasks.get(task.ID)
+	stored.Touch(schema.TaskCompleted)
+	srv.tasks.put(stored)
+	if _, ok := srv.Credentials().Credential(context.Background(), task.ID, "calendar"); ok {
+		t.Fatal("credential kept after the task completed")
+	}
+}
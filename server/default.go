@@ -64,4 +64,8 @@ func (h *DefaultHandler) CompleteText(task *schema.Task, text string) {
 	h.DefaultOperations.srv.tasks.put(task)
 }
 
-// Note: demo streaming helpers are intentionally not exported to avoid public API bloat.
+// Helper: StreamDemo runs the built-in demo stream for task, emitting its
+// events over the transport carried by ctx.
+func (h *DefaultHandler) StreamDemo(ctx context.Context, task *schema.Task) {
+	h.DefaultOperations.streamDemo(ctx, task)
+}
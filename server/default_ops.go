@@ -32,16 +32,15 @@ func NewDefaultOperations(srv *Server, tr transport.Transport, opts ...func(*Def
 func (d *DefaultOperations) Implements(method string) bool                             { return true }
 func (d *DefaultOperations) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
 
-func (d *DefaultOperations) AgentGetCard(_ context.Context, _ *jsonrpc.Request, resp *jsonrpc.Response) {
-	raw, _ := json.Marshal(d.srv.card)
-	resp.Result = raw
+func (d *DefaultOperations) AgentGetCard(ctx context.Context, _ *jsonrpc.Request, resp *jsonrpc.Response) {
+	writeExtendedCard(ctx, d.srv, resp)
 }
 
 func (d *DefaultOperations) pushSupported() bool {
-    return d.srv.card.PushNotificationsSupported()
+    return d.srv.pushSupported()
 }
 
-func (d *DefaultOperations) TasksPushNotificationConfigSet(_ context.Context, req *jsonrpc.Request, resp *jsonrpc.Response) {
+func (d *DefaultOperations) TasksPushNotificationConfigSet(ctx context.Context, req *jsonrpc.Request, resp *jsonrpc.Response) {
 	if !d.pushSupported() {
 		resp.Error = jsonrpc.NewError(-32003, "Push Notification is not supported", nil)
 		return
@@ -54,11 +53,7 @@ func (d *DefaultOperations) TasksPushNotificationConfigSet(_ context.Context, re
 		resp.Error = jsonrpc.NewInvalidParamsError("taskId and config required", req.Params)
 		return
 	}
-	if cfg := d.srv.tasks.addPush(p.TaskID, &p.Config); cfg != nil {
-		resp.Result, _ = json.Marshal(cfg)
-		return
-	}
-	resp.Error = jsonrpc.NewError(-32004, "not found", nil)
+	writePushConfigSet(ctx, d.srv, p.TaskID, &p.Config, req.Params, resp)
 }
 
 func (d *DefaultOperations) TasksPushNotificationConfigGet(_ context.Context, req *jsonrpc.Request, resp *jsonrpc.Response) {
@@ -75,7 +70,7 @@ func (d *DefaultOperations) TasksPushNotificationConfigGet(_ context.Context, re
         return
     }
 	if cfg, ok := d.srv.tasks.getPush(p.TaskID, p.ConfigID); ok {
-		resp.Result, _ = json.Marshal(cfg)
+		resp.Result, _ = json.Marshal(cfg.Redacted())
 		return
 	}
 	resp.Error = jsonrpc.NewError(-32004, "not found", nil)
@@ -94,7 +89,7 @@ func (d *DefaultOperations) TasksPushNotificationConfigList(_ context.Context, r
 		return
 	}
 	if cfgs, ok := d.srv.tasks.listPush(p.TaskID); ok {
-		resp.Result, _ = json.Marshal(cfgs)
+		resp.Result, _ = json.Marshal(redactPush(cfgs))
 		return
 	}
 	resp.Error = jsonrpc.NewError(-32004, "not found", nil)
@@ -130,6 +125,10 @@ func (d *DefaultOperations) MessageSend(ctx context.Context, req *jsonrpc.Reques
 		resp.Error = jsonrpc.NewInvalidParamsError("messages required", req.Params)
 		return
 	}
+	if jerr := d.srv.continueWithCredentials(ctx, p.TaskID, p.Messages, req.Params); jerr != nil {
+		resp.Error = jerr
+		return
+	}
 	if d.OnMessageSend != nil {
 		if task, jerr := d.OnMessageSend(ctx, p.Messages, p.ContextID, p.TaskID); jerr != nil {
 			resp.Error = jerr
@@ -148,7 +147,7 @@ func (d *DefaultOperations) MessageSend(ctx context.Context, req *jsonrpc.Reques
 			}
 		}
 	}
-	task := d.srv.tasks.newTask(p.ContextID)
+	task := d.srv.newTask(ctx, p.ContextID)
 	artifact := schema.Artifact{ID: "a-" + task.ID, CreatedAt: time.Now().UTC(), Parts: []schema.Part{schema.TextPart{Type: "text", Text: "ok"}}}
 	artifact.PartsRaw, _ = schema.MarshalParts(artifact.Parts)
 	task.Status = schema.TaskStatus{State: schema.TaskCompleted, UpdatedAt: time.Now().UTC()}
@@ -158,7 +157,7 @@ func (d *DefaultOperations) MessageSend(ctx context.Context, req *jsonrpc.Reques
 }
 
 func (d *DefaultOperations) MessageStream(ctx context.Context, req *jsonrpc.Request, resp *jsonrpc.Response) {
-    if !d.srv.card.StreamingSupported() {
+    if !d.srv.streamingSupported() {
         resp.Error = jsonrpc.NewError(-32002, "Streaming is not supported", nil)
         return
     }
@@ -171,6 +170,10 @@ func (d *DefaultOperations) MessageStream(ctx context.Context, req *jsonrpc.Requ
 		resp.Error = jsonrpc.NewInvalidParamsError("messages required", req.Params)
 		return
 	}
+	if jerr := d.srv.continueWithCredentials(ctx, p.TaskID, p.Messages, req.Params); jerr != nil {
+		resp.Error = jerr
+		return
+	}
 	if d.OnMessageStream != nil {
 		if task, jerr := d.OnMessageStream(ctx, p.Messages, p.ContextID, p.TaskID); jerr != nil {
 			resp.Error = jerr
@@ -187,7 +190,7 @@ func (d *DefaultOperations) MessageStream(ctx context.Context, req *jsonrpc.Requ
 			}
 		}
 	}
-	task := d.srv.tasks.newTask(p.ContextID)
+	task := d.srv.newTask(ctx, p.ContextID)
 	resp.Result, _ = json.Marshal(task)
 	go d.streamDemo(ctx, task)
 }
@@ -226,7 +229,7 @@ func (d *DefaultOperations) TasksCancel(_ context.Context, req *jsonrpc.Request,
 }
 
 func (d *DefaultOperations) TasksResubscribe(_ context.Context, req *jsonrpc.Request, resp *jsonrpc.Response) {
-    if !d.srv.card.StreamingSupported() {
+    if !d.srv.streamingSupported() {
         resp.Error = jsonrpc.NewError(-32002, "Streaming is not supported", nil)
         return
     }
@@ -2,13 +2,17 @@ package server
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "io"
     "net/http"
     "net/http/httptest"
     "testing"
+    "time"
 
     "github.com/viant/a2a-protocol/schema"
+    "github.com/viant/a2a-protocol/server/auth"
+    "github.com/viant/a2a-protocol/server/push"
 )
 
 // local copy to decode REST JSON-RPC responses
@@ -23,11 +27,13 @@ type rpcResp struct {
     } `json:"error,omitempty"`
 }
 
-func newTestServer(streaming, push bool) (*Server, *http.ServeMux) {
+func newTestServer(streaming, pushEnabled bool) (*Server, *http.ServeMux) {
     card := schema.AgentCard{Name: "test"}
     card.Endpoints = map[string]string{"rest": "/v1"}
-    card.SetCapabilities(schema.AgentCapabilities{Streaming: &streaming, PushNotifications: &push})
-    srv := New(card)
+    card.SetCapabilities(schema.AgentCapabilities{Streaming: &streaming, PushNotifications: &pushEnabled})
+    // test webhooks are plain-http loopback or placeholder hosts
+    validator := &push.URLValidator{AllowedHosts: []string{"127.0.0.1", "example.com"}}
+    srv := New(card, WithPushDispatcher(push.New(push.WithURLValidator(validator))))
     mux := http.NewServeMux()
     srv.RegisterJSONRPC(mux, "/rpc")
     return srv, mux
@@ -83,8 +89,7 @@ func TestRPC_MessageSend_And_Get(t *testing.T) {
     }
 }
 
-// Note: resubscribe is validated in gating_test via direct call; JSON-RPC handler
-// does not expose tasks/resubscribe in this minimal mapping.
+// Note: resubscribe gating is validated in gating_test.
 
 func TestRPC_PushEndpoints(t *testing.T) {
     // streaming true, push true
@@ -117,6 +122,17 @@ func TestRPC_PushEndpoints(t *testing.T) {
     // Delete
     rpc5 := rpcCall(t, ts, "tasks/pushNotificationConfig/delete", map[string]interface{}{"taskId": task.ID, "configId": "c1"})
     if rpc5.Error != nil { t.Fatalf("delete error: %+v", rpc5.Error) }
+
+    // Internal and plain-http webhook URLs are rejected
+    for _, url := range []string{"https://169.254.169.254/latest", "https://10.0.0.5/hook", "http://93.184.216.34/hook"} {
+        rpc6 := rpcCall(t, ts, "tasks/pushNotificationConfig/set", map[string]interface{}{
+            "taskId": task.ID,
+            "config": map[string]interface{}{"id": "c2", "url": url},
+        })
+        if rpc6.Error == nil || rpc6.Error.Code != -32602 {
+            t.Fatalf("expected invalid params for %s, got: %+v", url, rpc6.Error)
+        }
+    }
 }
 
 func TestRPC_Card_Capabilities_Object(t *testing.T) {
@@ -124,7 +140,46 @@ func TestRPC_Card_Capabilities_Object(t *testing.T) {
     ts := httptest.NewServer(mux)
     defer ts.Close()
 
+    // No extended card configured
     rpc := rpcCall(t, ts, "agent/getAuthenticatedExtendedCard", nil)
+    if rpc.Error == nil || rpc.Error.Code != -32007 {
+        t.Fatalf("expected extended card not configured (-32007), got: %+v", rpc.Error)
+    }
+
+    streaming := true
+    card := schema.AgentCard{Name: "test"}
+    card.SetCapabilities(schema.AgentCapabilities{Streaming: &streaming})
+    extended := card
+    extended.Skills = []schema.AgentSkill{{ID: "private", Name: "Private"}}
+    srv := New(card, WithExtendedCard(extended))
+    if !srv.Card().SupportsAuthenticatedExtendedCard {
+        t.Fatalf("public card should advertise supportsAuthenticatedExtendedCard")
+    }
+    mux2 := http.NewServeMux()
+    srv.RegisterJSONRPC(mux2, "/rpc")
+    srv.RegisterREST(mux2)
+    var principal *auth.Principal
+    ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        mux2.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+    }))
+    defer ts2.Close()
+
+    // anonymous callers never get the extended card, even without auth middleware
+    rpc = rpcCall(t, ts2, "agent/getAuthenticatedExtendedCard", nil)
+    if rpc.Error == nil || rpc.Error.Code != codeUnauthenticated {
+        t.Fatalf("anonymous card error: %+v", rpc.Error)
+    }
+    anonymous, err := http.Get(ts2.URL + "/v1/card")
+    if err != nil {
+        t.Fatalf("anonymous rest card: %v", err)
+    }
+    anonymous.Body.Close()
+    if anonymous.StatusCode != http.StatusUnauthorized {
+        t.Fatalf("anonymous rest card status=%d", anonymous.StatusCode)
+    }
+
+    principal = &auth.Principal{Subject: "alice"}
+    rpc = rpcCall(t, ts2, "agent/getAuthenticatedExtendedCard", nil)
     if rpc.Error != nil { t.Fatalf("card error: %+v", rpc.Error) }
     var obj map[string]json.RawMessage
     if err := json.Unmarshal(rpc.Result, &obj); err != nil {
@@ -134,4 +189,140 @@ func TestRPC_Card_Capabilities_Object(t *testing.T) {
     if len(capRaw) == 0 || capRaw[0] != '{' {
         t.Fatalf("capabilities shape = %s, want object", string(capRaw))
     }
+    if len(obj["skills"]) == 0 {
+        t.Fatalf("extended card missing skills: %s", string(rpc.Result))
+    }
+
+    resp, err := http.Get(ts2.URL + "/v1/card")
+    if err != nil {
+        t.Fatalf("rest card: %v", err)
+    }
+    defer resp.Body.Close()
+    var restCard schema.AgentCard
+    if err := json.NewDecoder(resp.Body).Decode(&restCard); err != nil || len(restCard.Skills) != 1 {
+        t.Fatalf("rest card = %+v err=%v, want extended card", restCard, err)
+    }
+
+    // a provider's shared card is flagged on a copy, never in place
+    shared := extended
+    srv = New(card, WithExtendedCardFunc(func(context.Context) (*schema.AgentCard, error) { return &shared, nil }))
+    got, err := srv.extendedCardFor(auth.WithPrincipal(context.Background(), principal))
+    if err != nil || !got.SupportsAuthenticatedExtendedCard || got == &shared || shared.SupportsAuthenticatedExtendedCard {
+        t.Fatalf("extended card = %+v err=%v, shared card mutated=%v", got, err, shared.SupportsAuthenticatedExtendedCard)
+    }
+}
+
+func TestRPC_PushDelivery(t *testing.T) {
+    delivered := make(chan schema.Task, 4)
+    hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var task schema.Task
+        _ = json.NewDecoder(r.Body).Decode(&task)
+        delivered <- task
+    }))
+    defer hook.Close()
+
+    _, mux := newTestServer(true, true)
+    ts := httptest.NewServer(mux)
+    defer ts.Close()
+
+    rpc := rpcCall(t, ts, "message/send", map[string]interface{}{
+        "messages": []map[string]interface{}{{"role": "user", "parts": []map[string]interface{}{{"type": "text", "text": "hi"}}}},
+    })
+    var task schema.Task
+    _ = json.Unmarshal(rpc.Result, &task)
+    rpc = rpcCall(t, ts, "tasks/pushNotificationConfig/set", map[string]interface{}{
+        "taskId": task.ID,
+        "config": map[string]interface{}{"id": "c1", "url": hook.URL},
+    })
+    if rpc.Error != nil { t.Fatalf("set error: %+v", rpc.Error) }
+
+    rpcCall(t, ts, "tasks/cancel", map[string]string{"id": task.ID})
+    select {
+    case got := <-delivered:
+        if got.ID != task.ID || got.Status.State != schema.TaskCanceled {
+            t.Fatalf("delivered task = %+v, want canceled %s", got, task.ID)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatalf("push notification not delivered")
+    }
+}
+
+func TestRPC_PushConfigRedactionFilterAndHistory(t *testing.T) {
+    delivered := make(chan schema.Task, 4)
+    hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var task schema.Task
+        _ = json.NewDecoder(r.Body).Decode(&task)
+        delivered <- task
+    }))
+    defer hook.Close()
+
+    srv, mux := newTestServer(true, true)
+    srv.RegisterREST(mux)
+    ts := httptest.NewServer(mux)
+    defer ts.Close()
+
+    rpc := rpcCall(t, ts, "message/send", map[string]interface{}{
+        "messages": []map[string]interface{}{{"role": "user", "parts": []map[string]interface{}{{"type": "text", "text": "hi"}}}},
+    })
+    var task schema.Task
+    _ = json.Unmarshal(rpc.Result, &task)
+
+    rpc = rpcCall(t, ts, "tasks/pushNotificationConfig/set", map[string]interface{}{
+        "taskId": task.ID,
+        "config": map[string]interface{}{"id": "c1", "url": hook.URL, "events": []string{"bogus"}},
+    })
+    if rpc.Error == nil || rpc.Error.Code != -32602 {
+        t.Fatalf("expected invalid params for unknown event, got: %+v", rpc.Error)
+    }
+    responses := []rpcResp{
+        rpcCall(t, ts, "tasks/pushNotificationConfig/set", map[string]interface{}{
+            "taskId": task.ID,
+            "config": map[string]interface{}{"id": "c1", "url": hook.URL, "token": "notify-token", "secret": "s3cret", "events": []string{"final"},
+                "authentication": map[string]interface{}{"schemes": []string{"Bearer"}, "credentials": "hook-token"}},
+        }),
+        rpcCall(t, ts, "tasks/pushNotificationConfig/set", map[string]interface{}{
+            "taskId": task.ID,
+            "config": map[string]interface{}{"id": "c2", "url": hook.URL, "events": []string{"artifact"}},
+        }),
+        rpcCall(t, ts, "tasks/pushNotificationConfig/get", map[string]interface{}{"taskId": task.ID, "configId": "c1"}),
+        rpcCall(t, ts, "tasks/pushNotificationConfig/list", map[string]interface{}{"taskId": task.ID}),
+    }
+    for _, resp := range responses {
+        if resp.Error != nil { t.Fatalf("unexpected error: %+v", resp.Error) }
+        if bytes.Contains(resp.Result, []byte("s3cret")) || bytes.Contains(resp.Result, []byte("hook-token")) || bytes.Contains(resp.Result, []byte("notify-token")) {
+            t.Fatalf("secret returned: %s", resp.Result)
+        }
+    }
+
+    rpcCall(t, ts, "tasks/cancel", map[string]string{"id": task.ID})
+    select {
+    case got := <-delivered:
+        if got.Status.State != schema.TaskCanceled {
+            t.Fatalf("delivered task = %+v, want canceled", got)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatalf("push notification not delivered")
+    }
+
+    var status struct {
+        Delivered int `json:"delivered"`
+        Attempts  []struct {
+            StatusCode int `json:"statusCode"`
+        } `json:"attempts"`
+    }
+    deadline := time.Now().Add(2 * time.Second)
+    for status.Delivered == 0 {
+        if time.Now().After(deadline) { t.Fatalf("delivery not recorded") }
+        time.Sleep(5 * time.Millisecond)
+        resp, err := http.Get(ts.URL + "/v1/tasks/" + task.ID + "/pushNotificationConfigs/c1/deliveries")
+        if err != nil { t.Fatalf("deliveries: %v", err) }
+        _ = json.NewDecoder(resp.Body).Decode(&status)
+        resp.Body.Close()
+    }
+    if len(status.Attempts) != 1 || status.Attempts[0].StatusCode != http.StatusOK {
+        t.Fatalf("attempts = %+v", status.Attempts)
+    }
+    if history, ok := srv.PushDeliveries(task.ID, "c2"); !ok || len(history.Attempts) != 0 {
+        t.Fatalf("artifact-only config should not receive status changes: %+v", history)
+    }
 }
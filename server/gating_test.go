@@ -3,7 +3,9 @@ package server
 import (
     "context"
     "encoding/json"
+    "net/http"
     "net/http/httptest"
+    "sync"
     "testing"
 
     "github.com/viant/a2a-protocol/schema"
@@ -17,10 +19,10 @@ func TestStreamingGating(t *testing.T) {
     card.SetCapabilities(schema.AgentCapabilities{Streaming: &sFalse, PushNotifications: &pFalse})
     srv := New(card)
 
-    // rpcResubscribe should return error -32002
+    // tasks/resubscribe should return error -32002
     rr := httptest.NewRecorder()
     params := json.RawMessage(`{"id":"t1"}`)
-    srv.rpcResubscribe(rr, rpcRequest{JSONRPC: "2.0", ID: []byte("1"), Method: "tasks/resubscribe", Params: &params})
+    srv.dispatchRPC(context.Background(), rr, rpcRequest{JSONRPC: "2.0", ID: []byte("1"), Method: "tasks/resubscribe", Params: &params})
     var resp rpcResponse
     if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
         t.Fatalf("decode response: %v", err)
@@ -58,10 +60,10 @@ func TestPushNotificationGating(t *testing.T) {
     card.SetCapabilities(schema.AgentCapabilities{Streaming: &sFalse, PushNotifications: &pFalse})
     srv := New(card)
 
-    // rpcPushConfigSet should return -32003 when push not supported
+    // tasks/pushNotificationConfig/set should return -32003 when push not supported
     rr := httptest.NewRecorder()
     params := json.RawMessage(`{"taskId":"t1","config":{"id":"c1","url":"https://example"}}`)
-    srv.rpcPushConfigSet(rr, rpcRequest{JSONRPC: "2.0", ID: []byte("1"), Method: "tasks/pushNotificationConfig/set", Params: &params})
+    srv.dispatchRPC(context.Background(), rr, rpcRequest{JSONRPC: "2.0", ID: []byte("1"), Method: "tasks/pushNotificationConfig/set", Params: &params})
     var resp rpcResponse
     if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
         t.Fatalf("decode response: %v", err)
@@ -71,3 +73,58 @@ func TestPushNotificationGating(t *testing.T) {
     }
 }
 
+func TestUpdateCard_Gating_And_ETag(t *testing.T) {
+    sFalse := false
+    card := schema.AgentCard{Name: "test"}
+    card.SetCapabilities(schema.AgentCapabilities{Streaming: &sFalse})
+    srv := New(card)
+    mux := http.NewServeMux()
+    srv.RegisterWellKnown(mux)
+
+    fetch := func() *httptest.ResponseRecorder {
+        rr := httptest.NewRecorder()
+        mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, WellKnownAgentCardPath, nil))
+        return rr
+    }
+    etag := fetch().Header().Get("ETag")
+
+    ops := NewOperations(srv, nil)
+    params := json.RawMessage(`{"messages":[{"role":"user","parts":[{"type":"text","text":"hi"}]}]}`)
+    var r jsonrpc.Response
+    ops.MessageStream(context.Background(), &jsonrpc.Request{Method: "message/stream", Params: params}, &r)
+    if r.Error == nil || r.Error.Code != -32002 {
+        t.Fatalf("expected streaming not supported before update, got: %+v", r.Error)
+    }
+
+    // Concurrent readers while updating must be safe (run with -race).
+    var wg sync.WaitGroup
+    for i := 0; i < 4; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 50; j++ {
+                _ = fetch()
+            }
+        }()
+    }
+    sTrue := true
+    updated := schema.AgentCard{Name: "test"}
+    updated.SetCapabilities(schema.AgentCapabilities{Streaming: &sTrue})
+    srv.UpdateCard(updated)
+    wg.Wait()
+
+    var r2 jsonrpc.Response
+    ops.MessageStream(context.Background(), &jsonrpc.Request{Method: "message/stream", Params: params}, &r2)
+    if r2.Error != nil {
+        t.Fatalf("unexpected error after enabling streaming: %+v", r2.Error)
+    }
+    rr := fetch()
+    if rr.Header().Get("ETag") == etag {
+        t.Fatalf("ETag unchanged after UpdateCard")
+    }
+    var got schema.AgentCard
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if !got.StreamingSupported() {
+        t.Fatalf("well-known card not updated")
+    }
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/viant/jsonrpc"
+)
+
+// MethodHandler executes a single A2A JSON-RPC method.
+type MethodHandler func(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response)
+
+// Interceptor wraps every A2A method invocation on every transport. It may
+// inspect or modify the request, short-circuit by setting response.Error
+// without calling next, or post-process the response after next returns.
+type Interceptor func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler)
+
+// WithInterceptors appends interceptors to the server's chain. The first
+// interceptor is the outermost one.
+func WithInterceptors(interceptors ...Interceptor) ServerOption {
+	return func(s *Server) { s.interceptors = append(s.interceptors, interceptors...) }
+}
+
+// chain composes interceptors around final, preserving registration order.
+func chain(interceptors []Interceptor, final MethodHandler) MethodHandler {
+	handler := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+			interceptor(ctx, request.Method, request, response, next)
+		}
+	}
+	return handler
+}
+
+// RecoverInterceptor converts a panic in a method handler into an
+// InternalError (-32603) response and logs the stack trace.
+func RecoverInterceptor() Interceptor {
+	return func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("a2a: panic in %s: %v\n%s", method, r, debug.Stack())
+				response.Result = nil
+				response.Error = jsonrpc.NewError(-32603, "internal error", fmt.Sprint(r))
+			}
+		}()
+		next(ctx, request, response)
+	}
+}
+
+// SlowCallInterceptor logs methods taking longer than threshold. When logf
+// is nil, log.Printf is used.
+func SlowCallInterceptor(threshold time.Duration, logf func(format string, args ...interface{})) Interceptor {
+	if logf == nil {
+		logf = log.Printf
+	}
+	return func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+		started := time.Now()
+		next(ctx, request, response)
+		if elapsed := time.Since(started); elapsed >= threshold {
+			logf("a2a: slow call %s took %s", method, elapsed)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	base "github.com/viant/jsonrpc/transport/server/base"
+)
+
+type panicOps struct{ Operations }
+
+func (p *panicOps) TasksGet(context.Context, *jsonrpc.Request, *jsonrpc.Response) {
+	panic("boom")
+}
+
+func TestInterceptors(t *testing.T) {
+	var order []string
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+			order = append(order, name+">"+method)
+			next(ctx, request, response)
+			order = append(order, name+"<")
+		}
+	}
+	deny := func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+		if method == "tasks/cancel" {
+			response.Error = jsonrpc.NewError(-32004, "denied", nil)
+			return
+		}
+		next(ctx, request, response)
+	}
+	srv := New(schema.AgentCard{Name: "test"},
+		WithOperations(func(srv *Server, _ transport.Transport) Operations { return &panicOps{NewOperations(srv, nil)} }),
+		WithInterceptors(RecoverInterceptor(), trace("a"), trace("b"), deny),
+	)
+	mux := http.NewServeMux()
+	srv.RegisterJSONRPC(mux, "/rpc")
+	srv.RegisterREST(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	rpc := rpcCall(t, ts, "tasks/get", map[string]string{"id": "t-1"})
+	if rpc.Error == nil || rpc.Error.Code != -32603 {
+		t.Fatalf("expected internal error from recovered panic, got: %+v", rpc.Error)
+	}
+	if got := strings.Join(order, ","); got != "a>tasks/get,b>tasks/get" {
+		t.Fatalf("order=%s", got)
+	}
+
+	order = nil
+	resp, err := http.Post(ts.URL+"/v1/tasks/t-1:cancel", "application/json", nil)
+	if err != nil {
+		t.Fatalf("rest cancel: %v", err)
+	}
+	resp.Body.Close()
+	if got := strings.Join(order, ","); got != "a>tasks/cancel,b>tasks/cancel,b<,a<" {
+		t.Fatalf("REST route not intercepted, order=%s", got)
+	}
+
+	order = nil
+	resp, err = http.Get(ts.URL + "/v1/tasks/t-1/pushNotificationConfigs/c1/deliveries")
+	if err != nil {
+		t.Fatalf("rest deliveries: %v", err)
+	}
+	resp.Body.Close()
+	if got := strings.Join(order, ","); resp.StatusCode != http.StatusNotImplemented || got != "a>"+pushDeliveriesMethod+",b>"+pushDeliveriesMethod+",b<,a<" {
+		t.Fatalf("deliveries route not intercepted, status=%d order=%s", resp.StatusCode, got)
+	}
+}
+
+func TestSessionPrincipal(t *testing.T) {
+	var seen []string
+	capture := func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+		if p, ok := auth.PrincipalFromContext(ctx); ok {
+			seen = append(seen, p.Subject+":"+strings.Join(p.Scopes, " "))
+		}
+		next(ctx, request, response)
+	}
+	srv := New(schema.AgentCard{Name: "test"}, WithInterceptors(capture))
+	alice := &auth.Principal{Subject: "alice", Method: auth.MethodJWT}
+	handler := newA2AHandler(srv)(auth.WithPrincipal(context.Background(), alice), nil)
+	session := func(id string) context.Context {
+		return context.WithValue(context.Background(), jsonrpc.SessionKey, &base.Session{Id: id})
+	}
+	// bob reached s-2 before alice's first message was handled
+	srv.sessions.admit("s-2", &auth.Principal{Subject: "bob"})
+
+	testCases := []struct {
+		description string
+		ctx         context.Context
+		admit       *auth.Principal
+		expect      string
+	}{
+		// the transport handles messages on a context unrelated to the session handshake
+		{description: "session principal", ctx: context.Background(), expect: "alice:"},
+		{description: "same identity on the message", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Scopes: []string{"tasks.read"}}), expect: "alice:tasks.read"},
+		{description: "other identity on the message", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "bob"})},
+		{description: "latest admitted request", ctx: session("s-1"), admit: &auth.Principal{Subject: "alice", Scopes: []string{"tasks.write"}}, expect: "alice:tasks.write"},
+		{description: "session admitted for another identity", ctx: session("s-2")},
+		{description: "session reclaimed by its creator", ctx: session("s-2"), expect: "alice:"},
+		{description: "expired credentials", ctx: auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Claims: map[string]interface{}{"exp": float64(time.Now().Add(-time.Minute).Unix())}})},
+	}
+	for _, testCase := range testCases {
+		seen = nil
+		if testCase.admit != nil && !srv.sessions.admit("s-1", testCase.admit) {
+			t.Fatalf("%s: request not admitted", testCase.description)
+		}
+		response := &jsonrpc.Response{}
+		handler.Serve(testCase.ctx, &jsonrpc.Request{Method: "tasks/get", Params: []byte(`{"id":"missing"}`)}, response)
+		if strings.Join(seen, ",") != testCase.expect {
+			t.Errorf("%s: principals=%v", testCase.description, seen)
+		}
+		if testCase.expect == "" && (response.Error == nil || response.Error.Code != -32600) {
+			t.Errorf("%s: error=%+v", testCase.description, response.Error)
+		}
+	}
+	if srv.sessions.admit("s-2", &auth.Principal{Subject: "bob"}) {
+		t.Fatal("reclaimed session admitted another identity")
+	}
+}
+
+func TestSessionOwner(t *testing.T) {
+	srv := New(schema.AgentCard{Name: "test"})
+	// the transport stands in for SSE and Streamable HTTP, which only accept
+	// sessions they opened themselves
+	transport := srv.withSessionOwner(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var principal *auth.Principal
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}))
+	defer ts.Close()
+
+	alice, bob := &auth.Principal{Subject: "alice"}, &auth.Principal{Subject: "bob"}
+	testCases := []struct {
+		description string
+		principal   *auth.Principal
+		path        string
+		session     string
+		expect      int
+	}{
+		{description: "streamable first request binds the session", principal: alice, path: "/a2a", session: "s-1", expect: http.StatusOK},
+		{description: "streamable other identity", principal: bob, path: "/a2a", session: "s-1", expect: http.StatusNotFound},
+		{description: "streamable anonymous", path: "/a2a", session: "s-1", expect: http.StatusNotFound},
+		{description: "streamable same identity", principal: alice, path: "/a2a", session: "s-1", expect: http.StatusOK},
+		{description: "sse first request binds the session", principal: bob, path: "/v1/message:send?session_id=s-2", expect: http.StatusOK},
+		{description: "sse other identity", principal: alice, path: "/v1/message:send?session_id=s-2", expect: http.StatusNotFound},
+		{description: "no session", principal: bob, path: "/a2a", expect: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		principal = testCase.principal
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+testCase.path, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"missing"}}`))
+		if testCase.session != "" {
+			req.Header.Set(sessionHeader, testCase.session)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.description, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != testCase.expect {
+			t.Errorf("%s: status=%d want %d", testCase.description, resp.StatusCode, testCase.expect)
+		}
+	}
+}
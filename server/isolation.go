@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+// TaskOwner identifies the principal that created a task.
+type TaskOwner struct {
+	Tenant  string `json:"tenant,omitempty"`
+	Subject string `json:"subject"`
+}
+
+// TaskGrant gives another principal access to a task. An empty Subject
+// grants every principal of Tenant.
+type TaskGrant struct {
+	Tenant  string `json:"tenant,omitempty"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// ErrTaskNotFound is returned for tasks that do not exist or that the caller
+// may not access.
+var ErrTaskNotFound = errors.New("task not found")
+
+// taskNotFound is the error every task method returns for a missing task, so
+// tasks owned by other callers are indistinguishable from missing ones.
+func taskNotFound() *jsonrpc.Error {
+	return jsonrpc.NewError(-32004, "not found", nil)
+}
+
+// errNoIdentity rejects authenticated callers that tasks cannot be scoped to.
+var errNoIdentity = errors.New("authenticated caller has no sub, client_id, azp or key_id")
+
+// ownerOf returns the owner identity of principal. Only a request without
+// credentials, a nil principal, is anonymous (ok false); an authenticated
+// principal without a subject falls back to its client_id, azp or key_id
+// claim and is rejected with errNoIdentity when it has none.
+func ownerOf(principal *auth.Principal) (owner TaskOwner, ok bool, err error) {
+	if principal == nil {
+		return TaskOwner{}, false, nil
+	}
+	subject := principal.Subject
+	for _, claim := range []string{"client_id", "azp", "key_id"} {
+		if subject != "" {
+			break
+		}
+		subject, _ = principal.Claims[claim].(string)
+	}
+	if subject == "" {
+		return TaskOwner{}, false, errNoIdentity
+	}
+	return TaskOwner{Tenant: principal.Tenant, Subject: subject}, true, nil
+}
+
+// TaskOwner returns the principal recorded as the creator of taskID.
+func (s *Server) TaskOwner(taskID string) (TaskOwner, bool) {
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+	owner, ok := s.tasks.owners[taskID]
+	return owner, ok
+}
+
+// ShareTask lets grant access taskID. Only the task's owner may share it.
+func (s *Server) ShareTask(ctx context.Context, taskID string, grant TaskGrant) error {
+	s.tasks.mu.Lock()
+	defer s.tasks.mu.Unlock()
+	if !s.ownsTask(ctx, taskID) {
+		return ErrTaskNotFound
+	}
+	for _, existing := range s.tasks.grants[taskID] {
+		if existing == grant {
+			return nil
+		}
+	}
+	s.tasks.grants[taskID] = append(s.tasks.grants[taskID], grant)
+	return nil
+}
+
+// UnshareTask revokes grant on taskID. Only the task's owner may revoke it.
+func (s *Server) UnshareTask(ctx context.Context, taskID string, grant TaskGrant) error {
+	s.tasks.mu.Lock()
+	defer s.tasks.mu.Unlock()
+	if !s.ownsTask(ctx, taskID) {
+		return ErrTaskNotFound
+	}
+	grants := s.tasks.grants[taskID][:0]
+	for _, existing := range s.tasks.grants[taskID] {
+		if existing != grant {
+			grants = append(grants, existing)
+		}
+	}
+	s.tasks.grants[taskID] = grants
+	return nil
+}
+
+// TaskGrants lists the grants on taskID for its owner.
+func (s *Server) TaskGrants(ctx context.Context, taskID string) ([]TaskGrant, error) {
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+	if !s.ownsTask(ctx, taskID) {
+		return nil, ErrTaskNotFound
+	}
+	return append([]TaskGrant(nil), s.tasks.grants[taskID]...), nil
+}
+
+// ownsTask reports whether the caller is the recorded owner of taskID. Tasks
+// created without credentials have no owner and cannot be shared; callers
+// hold tasks.mu.
+func (s *Server) ownsTask(ctx context.Context, taskID string) bool {
+	if _, ok := s.tasks.items[taskID]; !ok {
+		return false
+	}
+	owner, owned := s.tasks.owners[taskID]
+	if !owned {
+		return false
+	}
+	principal, _ := auth.PrincipalFromContext(ctx)
+	caller, ok, _ := ownerOf(principal)
+	return ok && caller == owner
+}
+
+// canAccessTask reports whether the caller may see taskID: tasks created
+// without credentials are open, others only to their owner and grantees.
+func (s *Server) canAccessTask(ctx context.Context, taskID string) bool {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+	return s.tasks.accessible(taskID, principal)
+}
+
+// accessible implements canAccessTask; callers hold mu.
+func (t *taskStore) accessible(taskID string, principal *auth.Principal) bool {
+	caller, ok, err := ownerOf(principal)
+	if err != nil {
+		return false
+	}
+	owner, owned := t.owners[taskID]
+	if !owned {
+		return true
+	}
+	if !ok {
+		return false
+	}
+	if caller == owner {
+		return true
+	}
+	for _, grant := range t.grants[taskID] {
+		if grant.Tenant == caller.Tenant && (grant.Subject == "" || grant.Subject == caller.Subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// claimTask records the caller as the owner of a task it created.
+func (s *Server) claimTask(ctx context.Context, taskID string) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	owner, ok, _ := ownerOf(principal)
+	if !ok || taskID == "" {
+		return
+	}
+	s.tasks.mu.Lock()
+	defer s.tasks.mu.Unlock()
+	if _, exists := s.tasks.items[taskID]; !exists {
+		return
+	}
+	if _, owned := s.tasks.owners[taskID]; !owned {
+		s.tasks.owners[taskID] = owner
+	}
+}
+
+// newTask creates a task owned by the caller.
+func (s *Server) newTask(ctx context.Context, contextID *string) *schema.Task {
+	task := s.tasks.newTask(contextID)
+	s.claimTask(ctx, task.ID)
+	return task
+}
+
+// isolate is the interceptor scoping task methods to the caller. Tasks of
+// other callers are answered exactly like missing ones, and tasks created by
+// message/send or message/stream are claimed for the caller. Authenticated
+// callers without an identity are rejected.
+func (s *Server) isolate(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+	var p struct {
+		ID     string `json:"id"`
+		TaskID string `json:"taskId"`
+	}
+	switch method {
+	case "tasks/get", "tasks/cancel", "tasks/resubscribe":
+		_ = json.Unmarshal(request.Params, &p)
+		p.TaskID = p.ID
+	case "tasks/pushNotificationConfig/set", "tasks/pushNotificationConfig/get",
+		"tasks/pushNotificationConfig/list", "tasks/pushNotificationConfig/delete",
+		pushDeliveriesMethod, "message/send", "message/stream":
+		_ = json.Unmarshal(request.Params, &p)
+	case listTasksMethod:
+	default:
+		next(ctx, request, response)
+		return
+	}
+	principal, _ := auth.PrincipalFromContext(ctx)
+	if _, _, err := ownerOf(principal); err != nil {
+		response.Error = jsonrpc.NewError(-32600, err.Error(), nil)
+		return
+	}
+	if p.TaskID != "" && !s.canAccessTask(ctx, p.TaskID) {
+		response.Error = taskNotFound()
+		return
+	}
+	next(ctx, request, response)
+	if (method == "message/send" || method == "message/stream") && response.Error == nil {
+		var task struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(response.Result, &task) == nil {
+			s.claimTask(ctx, task.ID)
+		}
+	}
+}
+
+// listTasksMethod names GET /v1/tasks in the interceptor chain, so rate
+// limits and audit apply to it; it is not exposed as a JSON-RPC method.
+const listTasksMethod = "tasks/list"
+
+// listTasks returns the tasks the caller may access.
+func (s *Server) listTasks(ctx context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	all := s.tasks.listTasks()
+	tasks := make([]*schema.Task, 0, len(all))
+	s.tasks.mu.RLock()
+	for _, task := range all {
+		if s.tasks.accessible(task.ID, principal) {
+			tasks = append(tasks, task)
+		}
+	}
+	s.tasks.mu.RUnlock()
+	response.Result, _ = json.Marshal(tasks)
+}
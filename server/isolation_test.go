@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+func TestTaskIsolation(t *testing.T) {
+	var listed int
+	srv := New(schema.AgentCard{Name: "test"}, WithInterceptors(func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+		if method == listTasksMethod {
+			listed++
+		}
+		next(ctx, request, response)
+	}))
+	mux := http.NewServeMux()
+	srv.RegisterJSONRPC(mux, "/rpc")
+	srv.RegisterREST(mux)
+	// callers are identified by X-User and X-Tenant, standing in for the auth middleware
+	principal := func(r *http.Request) *auth.Principal {
+		switch user := r.Header.Get("X-User"); {
+		case strings.HasPrefix(user, "client:"):
+			// client-credentials tokens carry client_id but no sub
+			return &auth.Principal{Tenant: r.Header.Get("X-Tenant"), Claims: map[string]interface{}{"client_id": strings.TrimPrefix(user, "client:")}}
+		case user == "unidentified":
+			return &auth.Principal{Method: auth.MethodBearer}
+		case user != "":
+			return &auth.Principal{Subject: user, Tenant: r.Header.Get("X-Tenant")}
+		}
+		return nil
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := principal(r); p != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), p))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	do := func(method, path, user, tenant string, body []byte) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		req.Header.Set("X-User", user)
+		req.Header.Set("X-Tenant", tenant)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+	call := func(user, tenant, method string, params interface{}) rpcResp {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		resp := do(http.MethodPost, "/rpc", user, tenant, body)
+		defer resp.Body.Close()
+		var out rpcResp
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+	send := map[string]interface{}{"messages": []map[string]interface{}{{"role": "user", "parts": []map[string]interface{}{{"type": "text", "text": "hi"}}}}}
+	var task schema.Task
+	if rpc := call("alice", "acme", "message/send", send); rpc.Error != nil || json.Unmarshal(rpc.Result, &task) != nil {
+		t.Fatalf("send: %+v", rpc.Error)
+	}
+	if owner, ok := srv.TaskOwner(task.ID); !ok || owner != (TaskOwner{Tenant: "acme", Subject: "alice"}) {
+		t.Fatalf("owner=%+v", owner)
+	}
+	var clientTask schema.Task
+	if rpc := call("client:billing", "acme", "message/send", send); rpc.Error != nil || json.Unmarshal(rpc.Result, &clientTask) != nil {
+		t.Fatalf("client send: %+v", rpc.Error)
+	}
+	if owner, ok := srv.TaskOwner(clientTask.ID); !ok || owner != (TaskOwner{Tenant: "acme", Subject: "billing"}) {
+		t.Fatalf("client owner=%+v", owner)
+	}
+	// an authenticated caller without any identity is not treated as anonymous
+	if rpc := call("unidentified", "", "message/send", send); rpc.Error == nil || rpc.Error.Code != -32600 {
+		t.Fatalf("unidentified send: %+v", rpc.Error)
+	}
+	alice := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Tenant: "acme"})
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "bob", Tenant: "acme"})
+
+	testCases := []struct {
+		description string
+		setup       func() error
+		user        string
+		tenant      string
+		method      string
+		params      interface{}
+		expectError bool
+	}{
+		{description: "owner reads", user: "alice", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}},
+		{description: "other subject", user: "bob", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "same subject, other tenant", user: "alice", tenant: "globex", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "anonymous", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "client without subject", user: "client:billing", tenant: "acme", method: "tasks/get", params: map[string]string{"id": clientTask.ID}},
+		{description: "client reads foreign task", user: "client:billing", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "subject matching client task", user: "bob", tenant: "acme", method: "tasks/get", params: map[string]string{"id": clientTask.ID}, expectError: true},
+		{description: "other subject cancels", user: "bob", tenant: "acme", method: "tasks/cancel", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "other subject continues", user: "bob", tenant: "acme", method: "message/send", params: map[string]interface{}{"taskId": task.ID, "messages": send["messages"]}, expectError: true},
+		{description: "other subject lists push configs", user: "bob", tenant: "acme", method: "tasks/pushNotificationConfig/list", params: map[string]string{"taskId": task.ID}, expectError: true},
+		{description: "grantee cannot share", setup: func() error {
+			if err := srv.ShareTask(bob, task.ID, TaskGrant{Tenant: "acme", Subject: "bob"}); err != ErrTaskNotFound {
+				t.Fatalf("bob shared: %v", err)
+			}
+			return nil
+		}, user: "bob", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "shared with subject", setup: func() error {
+			return srv.ShareTask(alice, task.ID, TaskGrant{Tenant: "acme", Subject: "bob"})
+		}, user: "bob", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}},
+		{description: "grant is tenant scoped", user: "bob", tenant: "globex", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "revoked", setup: func() error {
+			return srv.UnshareTask(alice, task.ID, TaskGrant{Tenant: "acme", Subject: "bob"})
+		}, user: "bob", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}, expectError: true},
+		{description: "shared with tenant", setup: func() error {
+			return srv.ShareTask(alice, task.ID, TaskGrant{Tenant: "acme"})
+		}, user: "carol", tenant: "acme", method: "tasks/get", params: map[string]string{"id": task.ID}},
+	}
+	for _, testCase := range testCases {
+		if testCase.setup != nil {
+			if err := testCase.setup(); err != nil {
+				t.Fatalf("%s: setup: %v", testCase.description, err)
+			}
+		}
+		rpc := call(testCase.user, testCase.tenant, testCase.method, testCase.params)
+		if testCase.expectError {
+			// foreign tasks look exactly like missing ones
+			if rpc.Error == nil || rpc.Error.Code != -32004 {
+				t.Fatalf("%s: error=%+v", testCase.description, rpc.Error)
+			}
+			continue
+		}
+		if rpc.Error != nil {
+			t.Fatalf("%s: unexpected error: %+v", testCase.description, rpc.Error)
+		}
+	}
+	if grants, err := srv.TaskGrants(alice, task.ID); err != nil || len(grants) != 1 {
+		t.Fatalf("grants=%+v err=%v", grants, err)
+	}
+	// REST routes are scoped the same way
+	list := func(user, tenant string) []schema.Task {
+		resp := do(http.MethodGet, "/v1/tasks", user, tenant, nil)
+		defer resp.Body.Close()
+		var tasks []schema.Task
+		_ = json.NewDecoder(resp.Body).Decode(&tasks)
+		return tasks
+	}
+	if tasks := list("dave", "initech"); len(tasks) != 0 {
+		t.Fatalf("foreign list=%+v", tasks)
+	}
+	if tasks := list("alice", "acme"); len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("owner list=%+v", tasks)
+	}
+	if tasks := list("unidentified", ""); len(tasks) != 0 {
+		t.Fatalf("unidentified list=%+v", tasks)
+	}
+	if listed != 3 {
+		t.Fatalf("list interceptor calls=%d", listed)
+	}
+	resp := do(http.MethodGet, "/v1/tasks/"+task.ID, "dave", "initech", nil)
+	defer resp.Body.Close()
+	var rpc rpcResp
+	if _ = json.NewDecoder(resp.Body).Decode(&rpc); rpc.Error == nil || rpc.Error.Code != -32004 {
+		t.Fatalf("rest get error=%+v", rpc.Error)
+	}
+	// tasks created without credentials have no owner to share them
+	var anonymousTask schema.Task
+	if rpc := call("", "", "message/send", send); rpc.Error != nil || json.Unmarshal(rpc.Result, &anonymousTask) != nil {
+		t.Fatalf("anonymous send: %+v", rpc.Error)
+	}
+	for _, ctx := range []context.Context{context.Background(), bob} {
+		if err := srv.ShareTask(ctx, anonymousTask.ID, TaskGrant{Tenant: "acme"}); err != ErrTaskNotFound {
+			t.Fatalf("anonymous task shared: %v", err)
+		}
+		if err := srv.UnshareTask(ctx, anonymousTask.ID, TaskGrant{Tenant: "acme"}); err != ErrTaskNotFound {
+			t.Fatalf("anonymous task unshared: %v", err)
+		}
+	}
+
+}
@@ -1,13 +1,21 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"time"
+	"strings"
+	"sync"
 
 	"github.com/viant/a2a-protocol/schema"
-	"strings"
+	"github.com/viant/a2a-protocol/server/audit"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/a2a-protocol/server/push"
+	"github.com/viant/jsonrpc"
 )
 
 // JSON-RPC 2.0 structures
@@ -33,40 +41,88 @@ type rpcError struct {
 
 // Server implements A2A entry points.
 type Server struct {
-	tasks      *taskStore
-	card       schema.AgentCard
-	opsFactory NewOperationsFunc
+	tasks            *taskStore
+	cardMu           sync.RWMutex
+	card             schema.AgentCard
+	cardProvider     CardProviderFunc
+	extendedCard     ExtendedCardFunc
+	cardCacheControl string
+	trustedProxies   []string
+	opsFactory       NewOperationsFunc
+	interceptors     []Interceptor
+	pushDispatcher   *push.Dispatcher
+	scopePolicy      *auth.ScopePolicy
+	credentials      CredentialBroker
+	limiter          *Limiter
+	auditLog         *audit.Logger
+	auditMethods     map[string]bool
+	auditError       func(entry audit.Entry, err error)
+	cors             *CORS
+	sessions         *sessionRegistry
+	maxRequestBytes  int64
+	maxBatchSize     int
+	batchConcurrency int
+	// ops serves the plain HTTP JSON-RPC and REST routes (no streaming transport).
+	opsOnce sync.Once
+	ops     Operations
 }
 
 // New creates a Server with an in-memory task store.
 func New(card schema.AgentCard, opts ...ServerOption) *Server {
-	s := &Server{tasks: newTaskStore(), card: card}
+	s := &Server{tasks: newTaskStore(), sessions: newSessionRegistry(), card: card, cardCacheControl: defaultCardCacheControl,
+		maxRequestBytes: DefaultMaxRequestBytes, maxBatchSize: DefaultMaxBatchSize, batchConcurrency: DefaultBatchConcurrency}
 	for _, o := range opts {
 		o(s)
 	}
+	if s.pushDispatcher == nil {
+		s.pushDispatcher = push.New()
+	}
+	if s.auditLog != nil {
+		s.interceptors = append(s.interceptors, s.audit)
+	}
+	if s.limiter != nil {
+		s.interceptors = append(s.interceptors, s.limit)
+	}
+	s.interceptors = append(s.interceptors, s.isolate)
+	if s.scopePolicy != nil {
+		s.interceptors = append(s.interceptors, s.authorize)
+	}
+	if s.credentials == nil {
+		s.credentials = NewCredentialBroker()
+	}
+	s.tasks.onUpdate = s.onTaskUpdate
 	return s
 }
 
 // RegisterJSONRPC registers a JSON-RPC handler on the given mux and path.
 func (s *Server) RegisterJSONRPC(mux *http.ServeMux, path string) {
-	mux.HandleFunc(path, s.handleJSONRPC)
+	s.handle(mux, path, s.withClient(s.handleJSONRPC))
 }
 
 // RegisterREST registers minimal REST handlers per mapping table.
 func (s *Server) RegisterREST(mux *http.ServeMux) {
     // POST /v1/message:send
-    mux.HandleFunc("/v1/message:send", func(w http.ResponseWriter, r *http.Request) {
+    s.handle(mux, "/v1/message:send", s.withClient(func(w http.ResponseWriter, r *http.Request) {
         if r.Method != http.MethodPost {
             http.NotFound(w, r)
             return
         }
         s.handleSendMessageREST(w, r)
-    })
+    }))
     // Consolidated handler for /v1/tasks/* routes to avoid conflicting mux patterns
-    mux.HandleFunc("/v1/tasks/", func(w http.ResponseWriter, r *http.Request) {
+    s.handle(mux, "/v1/tasks/", s.withClient(func(w http.ResponseWriter, r *http.Request) {
         path := r.URL.Path
         // Push notification subroutes
         if strings.Contains(path, "/pushNotificationConfigs/") {
+            // Delivery history: GET /v1/tasks/{id}/pushNotificationConfigs/{configId}/deliveries
+            if strings.HasSuffix(path, "/deliveries") {
+                if r.Method != http.MethodGet {
+                    http.NotFound(w, r)
+                    return
+                }
+                s.handlePushDeliveriesREST(w, r)
+                return
+            }
             switch r.Method {
             case http.MethodGet:
                 s.handleGetPushConfigREST(w, r)
@@ -116,15 +172,23 @@ func (s *Server) RegisterREST(mux *http.ServeMux) {
             return
         }
         http.NotFound(w, r)
-    })
+    }))
+	// GET /v1/card (authenticated extended agent card)
+	s.handle(mux, "/v1/card", s.withClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		s.handleExtendedCardREST(w, r)
+	}))
 	// GET /v1/tasks
-	mux.HandleFunc("/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+	s.handle(mux, "/v1/tasks", s.withClient(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.NotFound(w, r)
 			return
 		}
 		s.handleListTasksREST(w, r)
-	})
+	}))
 }
 
 func (s *Server) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
@@ -132,96 +196,84 @@ func (s *Server) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	var req rpcRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeRPCError(w, req.ID, -32700, "parse error", err)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxRequestBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			writeRPCError(w, nullID, -32600, "invalid request", fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeRPCError(w, nullID, -32700, "parse error", err)
 		return
 	}
-	switch req.Method {
-	case "message/send":
-		s.rpcSendMessage(w, req)
-	case "tasks/get":
-		s.rpcGetTask(w, req)
-	case "tasks/cancel":
-		s.rpcCancelTask(w, req)
-	case "tasks/pushNotificationConfig/set":
-		s.rpcPushConfigSet(w, req)
-	case "tasks/pushNotificationConfig/get":
-		s.rpcPushConfigGet(w, req)
-	case "tasks/pushNotificationConfig/list":
-		s.rpcPushConfigList(w, req)
-	case "tasks/pushNotificationConfig/delete":
-		s.rpcPushConfigDelete(w, req)
-	case "agent/getAuthenticatedExtendedCard":
-		writeRPCResult(w, req.ID, s.card)
-	default:
-		writeRPCError(w, req.ID, -32601, "method not found", nil)
-	}
-}
-
-// rpcSendMessage handles message/send and returns a Task.
-func (s *Server) rpcSendMessage(w http.ResponseWriter, req rpcRequest) {
-	type params struct {
-		ContextID *string          `json:"contextId,omitempty"`
-		Messages  []schema.Message `json:"messages"`
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		s.serveBatch(r.Context(), w, body)
+		return
 	}
-	var p params
-	if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || len(p.Messages) == 0 {
-		writeRPCError(w, req.ID, -32602, "invalid params", errors.New("messages required"))
+	req, rpcErr := decodeRPCRequest(body)
+	if rpcErr != nil {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", ID: nullID, Error: rpcErr})
 		return
 	}
-	task := s.tasks.newTask(p.ContextID)
-	// Stub: immediately mark as completed with an echo artifact
-	artifact := schema.Artifact{
-		ID:        "a-" + task.ID,
-		CreatedAt: time.Now().UTC(),
-		Parts:     []schema.Part{schema.TextPart{Type: "text", Text: "ok"}},
+	if req.isNotification() {
+		// Notifications are processed but never answered.
+		s.dispatchRPC(r.Context(), newResponseBuffer(), *req)
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
-	artifact.PartsRaw, _ = schema.MarshalParts(artifact.Parts)
-	task.Status = schema.TaskStatus{State: schema.TaskCompleted, UpdatedAt: time.Now().UTC()}
-	task.Artifacts = []schema.Artifact{artifact}
-	s.tasks.put(task)
-	writeRPCResult(w, req.ID, task)
+	w.Header().Set("Content-Type", "application/json")
+	s.dispatchRPC(r.Context(), w, *req)
 }
 
-func (s *Server) rpcGetTask(w http.ResponseWriter, req rpcRequest) {
-	type params struct {
-		ID string `json:"id"`
-	}
-	var p params
-	if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.ID == "" {
-		writeRPCError(w, req.ID, -32602, "invalid params", errors.New("id required"))
-		return
+// dispatchRPC invokes a validated JSON-RPC request through the server's
+// Operations and interceptor chain and writes the JSON-RPC response.
+func (s *Server) dispatchRPC(ctx context.Context, w http.ResponseWriter, req rpcRequest) {
+	request := &jsonrpc.Request{Jsonrpc: jsonrpc.Version, Id: requestID(req.ID), Method: req.Method}
+	if req.Params != nil {
+		request.Params = *req.Params
 	}
-	task, ok := s.tasks.get(p.ID)
-	if !ok {
-		writeRPCError(w, req.ID, -32004, "not found", nil)
-		return
+	response := &jsonrpc.Response{}
+	s.invoke(ctx, s.operations(), request, response)
+	if challengeInsufficientScope(w, response.Error) {
+		w.WriteHeader(http.StatusForbidden)
+	} else if retryAfter(w, response.Error) {
+		w.WriteHeader(http.StatusTooManyRequests)
 	}
-	writeRPCResult(w, req.ID, task)
+	writeRPCResponse(w, req.ID, response)
 }
 
-func (s *Server) rpcCancelTask(w http.ResponseWriter, req rpcRequest) {
-	type params struct {
-		ID string `json:"id"`
+// requestID converts a raw JSON-RPC id into a jsonrpc.RequestId, keeping numbers exact.
+func requestID(raw json.RawMessage) jsonrpc.RequestId {
+	if len(raw) == 0 {
+		return nil
 	}
-	var p params
-	if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.ID == "" {
-		writeRPCError(w, req.ID, -32602, "invalid params", errors.New("id required"))
-		return
-	}
-	if task, ok := s.tasks.get(p.ID); ok {
-		task.Status.State = schema.TaskCanceled
-		task.Status.UpdatedAt = time.Now().UTC()
-		s.tasks.put(task)
-		writeRPCResult(w, req.ID, task)
-		return
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var id interface{}
+	if err := dec.Decode(&id); err != nil {
+		return nil
 	}
-	writeRPCError(w, req.ID, -32004, "not found", nil)
+	return id
 }
 
-func writeRPCResult(w http.ResponseWriter, id json.RawMessage, result interface{}) {
-	_ = json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", ID: id, Result: result})
+func writeRPCResponse(w http.ResponseWriter, id json.RawMessage, response *jsonrpc.Response) {
+	if id == nil {
+		id = nullID
+	}
+	out := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  json.RawMessage `json:"result,omitempty"`
+		Error   *jsonrpc.Error  `json:"error,omitempty"`
+	}{JSONRPC: "2.0", ID: id, Result: response.Result, Error: response.Error}
+	if out.Error == nil && len(out.Result) == 0 {
+		out.Result = json.RawMessage("null")
+	}
+	_ = json.NewEncoder(w).Encode(out)
 }
 
 func writeRPCError(w http.ResponseWriter, id json.RawMessage, code int, message string, err error) {
@@ -231,106 +283,3 @@ func writeRPCError(w http.ResponseWriter, id json.RawMessage, code int, message
 	}
 	_ = json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", ID: id, Error: e})
 }
-
-func (s *Server) rpcResubscribe(w http.ResponseWriter, req rpcRequest) {
-    if !s.card.StreamingSupported() {
-        writeRPCError(w, req.ID, -32002, "Streaming is not supported", nil)
-        return
-    }
-    type params struct {
-        ID string `json:"id"`
-    }
-	var p params
-	if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.ID == "" {
-		writeRPCError(w, req.ID, -32602, "invalid params", nil)
-		return
-	}
-	if task, ok := s.tasks.get(p.ID); ok {
-		writeRPCResult(w, req.ID, task)
-		return
-	}
-	writeRPCError(w, req.ID, -32004, "not found", nil)
-}
-
-func (s *Server) pushSupported() bool {
-    return s.card.PushNotificationsSupported()
-}
-
-func (s *Server) rpcPushConfigSet(w http.ResponseWriter, req rpcRequest) {
-	if !s.pushSupported() {
-		writeRPCError(w, req.ID, -32003, "Push Notification is not supported", nil)
-		return
-	}
-	var p struct {
-		TaskID string                        `json:"taskId"`
-		Config schema.PushNotificationConfig `json:"config"`
-	}
-	if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.TaskID == "" {
-		writeRPCError(w, req.ID, -32602, "invalid params", nil)
-		return
-	}
-	if cfg := s.tasks.addPush(p.TaskID, &p.Config); cfg != nil {
-		writeRPCResult(w, req.ID, cfg)
-		return
-	}
-	writeRPCError(w, req.ID, -32004, "not found", nil)
-}
-
-func (s *Server) rpcPushConfigGet(w http.ResponseWriter, req rpcRequest) {
-    if !s.pushSupported() {
-        writeRPCError(w, req.ID, -32003, "Push Notification is not supported", nil)
-        return
-    }
-    var p struct {
-        TaskID  string `json:"taskId"`
-        ConfigID string `json:"configId"`
-    }
-    if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.TaskID == "" || p.ConfigID == "" {
-        writeRPCError(w, req.ID, -32602, "invalid params", nil)
-        return
-    }
-	if cfg, ok := s.tasks.getPush(p.TaskID, p.ConfigID); ok {
-		writeRPCResult(w, req.ID, cfg)
-		return
-	}
-	writeRPCError(w, req.ID, -32004, "not found", nil)
-}
-
-func (s *Server) rpcPushConfigList(w http.ResponseWriter, req rpcRequest) {
-	if !s.pushSupported() {
-		writeRPCError(w, req.ID, -32003, "Push Notification is not supported", nil)
-		return
-	}
-	var p struct {
-		TaskID string `json:"taskId"`
-	}
-	if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.TaskID == "" {
-		writeRPCError(w, req.ID, -32602, "invalid params", nil)
-		return
-	}
-	if cfgs, ok := s.tasks.listPush(p.TaskID); ok {
-		writeRPCResult(w, req.ID, cfgs)
-		return
-	}
-	writeRPCError(w, req.ID, -32004, "not found", nil)
-}
-
-func (s *Server) rpcPushConfigDelete(w http.ResponseWriter, req rpcRequest) {
-    if !s.pushSupported() {
-        writeRPCError(w, req.ID, -32003, "Push Notification is not supported", nil)
-        return
-    }
-    var p struct {
-        TaskID  string `json:"taskId"`
-        ConfigID string `json:"configId"`
-    }
-    if req.Params == nil || json.Unmarshal(*req.Params, &p) != nil || p.TaskID == "" || p.ConfigID == "" {
-        writeRPCError(w, req.ID, -32602, "invalid params", nil)
-        return
-    }
-	if ok := s.tasks.deletePush(p.TaskID, p.ConfigID); ok {
-		writeRPCResult(w, req.ID, map[string]bool{"deleted": true})
-		return
-	}
-	writeRPCError(w, req.ID, -32004, "not found", nil)
-}
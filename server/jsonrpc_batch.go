@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// nullID is used for responses to requests whose id could not be determined.
+var nullID = json.RawMessage("null")
+
+// isNotification reports whether the request carries no id member.
+func (r *rpcRequest) isNotification() bool { return r.ID == nil }
+
+// decodeRPCRequest parses and validates a single JSON-RPC 2.0 request envelope.
+// It returns -32700 for malformed JSON and -32600 for structurally invalid requests.
+func decodeRPCRequest(data []byte) (*rpcRequest, *rpcError) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || !json.Valid(data) {
+		return nil, &rpcError{Code: -32700, Message: "parse error"}
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, &rpcError{Code: -32600, Message: "invalid request", Data: "request must be an object"}
+	}
+	var version string
+	if raw, ok := envelope["jsonrpc"]; !ok || json.Unmarshal(raw, &version) != nil || version != "2.0" {
+		return nil, &rpcError{Code: -32600, Message: "invalid request", Data: `jsonrpc must be "2.0"`}
+	}
+	req := &rpcRequest{JSONRPC: version}
+	if raw, ok := envelope["method"]; !ok || json.Unmarshal(raw, &req.Method) != nil || req.Method == "" {
+		return nil, &rpcError{Code: -32600, Message: "invalid request", Data: "method must be a non-empty string"}
+	}
+	if raw, ok := envelope["id"]; ok {
+		if !isValidID(raw) {
+			return nil, &rpcError{Code: -32600, Message: "invalid request", Data: "id must be a string, number or null"}
+		}
+		req.ID = raw
+	}
+	if raw, ok := envelope["params"]; ok && string(raw) != "null" {
+		if raw[0] != '{' && raw[0] != '[' {
+			return nil, &rpcError{Code: -32600, Message: "invalid request", Data: "params must be an object or array"}
+		}
+		params := raw
+		req.Params = &params
+	}
+	return req, nil
+}
+
+func isValidID(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	switch c := raw[0]; {
+	case c == '"', c == '-', c >= '0' && c <= '9':
+		return true
+	}
+	return string(raw) == "null"
+}
+
+// serveBatch processes a JSON-RPC batch, running at most batchConcurrency
+// calls at once, and writes the responses in request order. Notifications are
+// executed but contribute no response.
+func (s *Server) serveBatch(ctx context.Context, w http.ResponseWriter, body []byte) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		writeRPCError(w, nullID, -32700, "parse error", err)
+		return
+	}
+	if len(items) == 0 {
+		writeRPCError(w, nullID, -32600, "invalid request", errors.New("empty batch"))
+		return
+	}
+	if len(items) > s.maxBatchSize {
+		writeRPCError(w, nullID, -32600, "invalid request", fmt.Errorf("batch exceeds %d calls", s.maxBatchSize))
+		return
+	}
+	results := make([]json.RawMessage, len(items))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, s.batchConcurrency)
+	for i, item := range items {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, item json.RawMessage) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			buf := newResponseBuffer()
+			req, rpcErr := decodeRPCRequest(item)
+			if rpcErr != nil {
+				_ = json.NewEncoder(buf).Encode(rpcResponse{JSONRPC: "2.0", ID: nullID, Error: rpcErr})
+			} else {
+				s.dispatchRPC(ctx, buf, *req)
+				if req.isNotification() {
+					return
+				}
+			}
+			results[i] = bytes.TrimSpace(buf.Bytes())
+		}(i, item)
+	}
+	wg.Wait()
+
+	responses := make([]json.RawMessage, 0, len(results))
+	for _, r := range results {
+		if len(r) > 0 {
+			responses = append(responses, r)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(responses)
+}
+
+// responseBuffer is an in-memory http.ResponseWriter used to capture the
+// output of a single JSON-RPC method handler.
+type responseBuffer struct {
+	bytes.Buffer
+	header http.Header
+	status int
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *responseBuffer) Header() http.Header { return b.header }
+
+func (b *responseBuffer) WriteHeader(status int) { b.status = status }
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/jsonrpc"
+)
+
+func postRaw(t *testing.T, ts *httptest.Server, body string) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/rpc", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("rpc post: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, data
+}
+
+func TestRPC_Batch(t *testing.T) {
+	_, mux := newTestServer(true, false)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	body := `[
+		{"jsonrpc":"2.0","id":"a","method":"message/send","params":{"messages":[{"role":"user","parts":[{"type":"text","text":"hi"}]}]}},
+		{"jsonrpc":"2.0","method":"message/send","params":{"messages":[{"role":"user","parts":[{"type":"text","text":"hi"}]}]}},
+		{"jsonrpc":"1.0","id":2,"method":"tasks/get"},
+		{"jsonrpc":"2.0","id":null,"method":"unknown"},
+		1
+	]`
+	_, data := postRaw(t, ts, body)
+	var out []rpcResp
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decode batch: %v body=%s", err, data)
+	}
+	if len(out) != 4 {
+		t.Fatalf("responses=%d want 4 (notification must be omitted): %s", len(out), data)
+	}
+	if string(out[0].ID) != `"a"` || out[0].Error != nil {
+		t.Fatalf("first response = %+v, want success with id \"a\"", out[0])
+	}
+	if out[1].Error == nil || out[1].Error.Code != -32600 || string(out[1].ID) != "null" {
+		t.Fatalf("second response = %+v, want -32600 with null id", out[1])
+	}
+	if out[2].Error == nil || out[2].Error.Code != -32601 || string(out[2].ID) != "null" {
+		t.Fatalf("third response = %+v, want -32601 with null id", out[2])
+	}
+	if out[3].Error == nil || out[3].Error.Code != -32600 {
+		t.Fatalf("fourth response = %+v, want -32600", out[3])
+	}
+
+	// batch calls run at most batchConcurrency at a time
+	var mu sync.Mutex
+	running, peak := 0, 0
+	srv := New(schema.AgentCard{Name: "test"}, WithRequestLimits(0, 0, 2), WithInterceptors(func(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		next(ctx, request, response)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}))
+	bounded := http.NewServeMux()
+	srv.RegisterJSONRPC(bounded, "/rpc")
+	ts2 := httptest.NewServer(bounded)
+	defer ts2.Close()
+	_, data = postRaw(t, ts2, "["+strings.TrimSuffix(strings.Repeat(`{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"x"}},`, 10), ",")+"]")
+	if err := json.Unmarshal(data, &out); err != nil || len(out) != 10 || peak != 2 {
+		t.Fatalf("responses=%d peak=%d err=%v", len(out), peak, err)
+	}
+}
+
+func TestRPC_Validation(t *testing.T) {
+	_, mux := newTestServer(true, false)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	testCases := []struct {
+		description string
+		body        string
+		code        int
+	}{
+		{description: "malformed json", body: `{"jsonrpc":`, code: -32700},
+		{description: "empty batch", body: `[]`, code: -32600},
+		{description: "missing version", body: `{"id":1,"method":"tasks/get"}`, code: -32600},
+		{description: "object id", body: `{"jsonrpc":"2.0","id":{},"method":"tasks/get"}`, code: -32600},
+		{description: "scalar params", body: `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":1}`, code: -32600},
+		{description: "oversized body", body: `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"` + strings.Repeat("x", DefaultMaxRequestBytes) + `"}}`, code: -32600},
+		{description: "oversized batch", body: "[" + strings.TrimSuffix(strings.Repeat(`{"jsonrpc":"2.0","id":1,"method":"tasks/get"},`, DefaultMaxBatchSize+1), ",") + "]", code: -32600},
+	}
+	for _, testCase := range testCases {
+		_, data := postRaw(t, ts, testCase.body)
+		var out rpcResp
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("%s: decode: %v body=%s", testCase.description, err, data)
+		}
+		if out.Error == nil || out.Error.Code != testCase.code {
+			t.Fatalf("%s: error=%+v want %d", testCase.description, out.Error, testCase.code)
+		}
+	}
+
+	resp, data := postRaw(t, ts, `{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"x"}}`)
+	if resp.StatusCode != http.StatusNoContent || len(data) != 0 {
+		t.Fatalf("notification: status=%d body=%s, want 204 with empty body", resp.StatusCode, data)
+	}
+
+	_, data = postRaw(t, ts, `{"jsonrpc":"2.0","id":7,"method":"tasks/get","params":{"id":"missing"}}`)
+	var out rpcResp
+	_ = json.Unmarshal(data, &out)
+	if string(out.ID) != "7" {
+		t.Fatalf("numeric id = %s, want 7", out.ID)
+	}
+}
@@ -0,0 +1,415 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+// codeRateLimited is returned when a caller exceeds a rate limit or quota.
+const codeRateLimited = -32011
+
+// maxIdleBuckets bounds the token buckets kept before idle ones are dropped.
+const maxIdleBuckets = 10000
+
+// Rate is a token bucket refilled at PerSecond up to Burst tokens. A zero
+// PerSecond means unlimited.
+type Rate struct {
+	PerSecond float64
+	// Burst defaults to PerSecond rounded up, at least 1.
+	Burst int
+}
+
+// Limits configures per-caller rate limits and quotas; see WithLimits.
+type Limits struct {
+	// Key identifies the caller; KeyByPrincipal when nil.
+	Key LimitKeyFunc
+	// Default applies to every method without an entry in Methods.
+	Default Rate
+	// Methods overrides Default per A2A method, e.g. "message/send".
+	Methods map[string]Rate
+	// MaxConcurrentTasks caps the non-terminal tasks a caller created with
+	// message/send or message/stream.
+	MaxConcurrentTasks int
+	// MaxStreams caps the SSE and Streamable HTTP streams a caller holds open.
+	MaxStreams int
+}
+
+// LimitKeyFunc returns the key limits are accounted under.
+type LimitKeyFunc func(ctx context.Context) string
+
+// KeyByPrincipal keys by the caller's tenant and identity, the subject or,
+// without one, the client_id, azp or key_id claim used for task ownership. It
+// falls back to the client IP for callers without an identity.
+func KeyByPrincipal(ctx context.Context) string {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	if owner, ok, _ := ownerOf(principal); ok {
+		return "principal:" + owner.Tenant + "/" + owner.Subject
+	}
+	return KeyByIP(ctx)
+}
+
+// KeyByAPIKey keys by the API key that authenticated the call, falling back
+// to KeyByPrincipal.
+func KeyByAPIKey(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.Method == auth.MethodAPIKey {
+		if id, _ := principal.Claims["key_id"].(string); id != "" {
+			return "apikey:" + id
+		}
+	}
+	return KeyByPrincipal(ctx)
+}
+
+// KeyByIP keys by the client IP of the HTTP request. For requests from a
+// proxy listed in WithTrustedProxies it is taken from X-Forwarded-For.
+func KeyByIP(ctx context.Context) string {
+	addr, _ := ctx.Value(clientAddrKey{}).(string)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "ip:" + addr
+}
+
+type clientAddrKey struct{}
+
+// withClientAddr records the client address of r for KeyByIP.
+func (s *Server) withClientAddr(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(clientAddrKey{}).(string); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), clientAddrKey{}, s.clientAddr(r)))
+}
+
+// clientAddr returns the peer address of r or, when the peer is a trusted
+// proxy, the nearest X-Forwarded-For address that is not one. Addresses left
+// of it may be forged by the client and are ignored.
+func (s *Server) clientAddr(r *http.Request) string {
+	if !auth.FromTrustedProxy(r, s.trustedProxies) {
+		return r.RemoteAddr
+	}
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		return r.RemoteAddr
+	}
+	for i := len(hops) - 1; i > 0; i-- {
+		if !auth.TrustedProxy(hops[i], s.trustedProxies) {
+			return hops[i]
+		}
+	}
+	return hops[0]
+}
+
+// WithLimits enforces limits on every transport. Calls over a rate limit or
+// quota fail with JSON-RPC error -32011, answered over plain HTTP with 429 and
+// Retry-After. Counters are available from Server.Limiter.
+func WithLimits(limits Limits) ServerOption {
+	return func(s *Server) { s.limiter = newLimiter(limits) }
+}
+
+// Limiter returns the server's limiter, or nil without WithLimits.
+func (s *Server) Limiter() *Limiter { return s.limiter }
+
+// Limiter tracks token buckets, running tasks and open streams per key.
+type Limiter struct {
+	limits  Limits
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	tasks   map[string]string
+	active  map[string]int
+	streams map[string]int
+	allowed map[string]uint64
+	limited map[string]uint64
+}
+
+type bucket struct {
+	tokens float64
+	burst  float64
+	rate   float64
+	last   time.Time
+}
+
+// LimitStats is a snapshot of the limiter counters.
+type LimitStats struct {
+	// Allowed and Limited count calls per method; "stream" counts streams.
+	Allowed     map[string]uint64 `json:"allowed"`
+	Limited     map[string]uint64 `json:"limited"`
+	ActiveTasks map[string]int    `json:"activeTasks"`
+	OpenStreams map[string]int    `json:"openStreams"`
+}
+
+func newLimiter(limits Limits) *Limiter {
+	if limits.Key == nil {
+		limits.Key = KeyByPrincipal
+	}
+	return &Limiter{
+		limits:  limits,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+		tasks:   map[string]string{},
+		active:  map[string]int{},
+		streams: map[string]int{},
+		allowed: map[string]uint64{},
+		limited: map[string]uint64{},
+	}
+}
+
+// Stats returns a copy of the current counters.
+func (l *Limiter) Stats() LimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := LimitStats{Allowed: map[string]uint64{}, Limited: map[string]uint64{}, ActiveTasks: map[string]int{}, OpenStreams: map[string]int{}}
+	for k, v := range l.allowed {
+		stats.Allowed[k] = v
+	}
+	for k, v := range l.limited {
+		stats.Limited[k] = v
+	}
+	for k, v := range l.active {
+		stats.ActiveTasks[k] = v
+	}
+	for k, v := range l.streams {
+		stats.OpenStreams[k] = v
+	}
+	return stats
+}
+
+// ServeHTTP writes Stats as JSON, for mounting on an internal monitoring route.
+func (l *Limiter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(l.Stats())
+}
+
+// take consumes a token of method's bucket for key, returning the wait
+// before the next token when none is left.
+func (l *Limiter) take(key, method string) (time.Duration, bool) {
+	rate, ok := l.limits.Methods[method]
+	if !ok {
+		rate = l.limits.Default
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rate.PerSecond <= 0 {
+		l.allowed[method]++
+		return 0, true
+	}
+	burst := float64(rate.Burst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(rate.PerSecond))
+	}
+	now := l.now()
+	id := key + "\x00" + method
+	b, ok := l.buckets[id]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: burst, burst: burst, rate: rate.PerSecond, last: now}
+		l.buckets[id] = b
+	}
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		l.limited[method]++
+		return time.Duration((1 - b.tokens) / b.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	l.allowed[method]++
+	return 0, true
+}
+
+// sweep drops buckets that have refilled completely; callers hold mu.
+func (l *Limiter) sweep(now time.Time) {
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+// reserveTask claims a running-task slot for key.
+func (l *Limiter) reserveTask(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[key] >= l.limits.MaxConcurrentTasks {
+		l.limited["tasks"]++
+		return false
+	}
+	l.active[key]++
+	return true
+}
+
+// assignTask binds a reserved slot to taskID, or frees it when the call did
+// not start a new running task.
+func (l *Limiter) assignTask(key, taskID string, running bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, tracked := l.tasks[taskID]; !running || tracked || taskID == "" {
+		l.release(l.active, key)
+		return
+	}
+	l.tasks[taskID] = key
+}
+
+// taskDone frees the slot of a task that reached a terminal state.
+func (l *Limiter) taskDone(taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if key, ok := l.tasks[taskID]; ok {
+		delete(l.tasks, taskID)
+		l.release(l.active, key)
+	}
+}
+
+func (l *Limiter) tracksTask(taskID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.tasks[taskID]
+	return ok
+}
+
+// openStream claims a stream slot for key.
+func (l *Limiter) openStream(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limits.MaxStreams > 0 && l.streams[key] >= l.limits.MaxStreams {
+		l.limited["stream"]++
+		return false
+	}
+	l.streams[key]++
+	l.allowed["stream"]++
+	return true
+}
+
+func (l *Limiter) closeStream(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.release(l.streams, key)
+}
+
+// release decrements counts[key]; callers hold mu.
+func (l *Limiter) release(counts map[string]int, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
+
+// limit is the interceptor installed by WithLimits.
+func (s *Server) limit(ctx context.Context, method string, request *jsonrpc.Request, response *jsonrpc.Response, next MethodHandler) {
+	l := s.limiter
+	key := l.limits.Key(ctx)
+	if wait, ok := l.take(key, method); !ok {
+		response.Error = rateLimited(wait)
+		return
+	}
+	if l.limits.MaxConcurrentTasks <= 0 || (method != "message/send" && method != "message/stream") {
+		next(ctx, request, response)
+		return
+	}
+	if taskID := continuedTask(request.Params); taskID != "" && l.tracksTask(taskID) {
+		next(ctx, request, response)
+		return
+	}
+	if !l.reserveTask(key) {
+		response.Error = jsonrpc.NewError(codeRateLimited, "too many running tasks: retry after 1s", &rateLimitData{RetryAfter: 1})
+		return
+	}
+	next(ctx, request, response)
+	var task struct {
+		ID     string `json:"id"`
+		Status struct {
+			State schema.TaskState `json:"state"`
+		} `json:"status"`
+	}
+	if response.Error == nil {
+		_ = json.Unmarshal(response.Result, &task)
+	}
+	l.assignTask(key, task.ID, task.ID != "" && !isTerminal(task.Status.State))
+	// the task may have finished while the result was being written
+	if stored, ok := s.tasks.get(task.ID); ok && isTerminal(stored.Status.State) {
+		l.taskDone(task.ID)
+	}
+}
+
+// continuedTask returns params.taskId of a message continuing a task.
+func continuedTask(params []byte) string {
+	var p struct {
+		TaskID string `json:"taskId"`
+	}
+	_ = json.Unmarshal(params, &p)
+	return p.TaskID
+}
+
+// rateLimitData is the data member of a rate-limited error.
+type rateLimitData struct {
+	// RetryAfter is the number of seconds to wait before retrying.
+	RetryAfter int `json:"retryAfter"`
+}
+
+func rateLimited(wait time.Duration) *jsonrpc.Error {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return jsonrpc.NewError(codeRateLimited, "rate limit exceeded: retry after "+strconv.Itoa(seconds)+"s", &rateLimitData{RetryAfter: seconds})
+}
+
+// retryAfter sets Retry-After for a rate-limited error answered over plain
+// HTTP, reporting whether the response should use 429.
+func retryAfter(w http.ResponseWriter, rpcErr *jsonrpc.Error) bool {
+	if rpcErr == nil || rpcErr.Code != codeRateLimited {
+		return false
+	}
+	data := &rateLimitData{}
+	if json.Unmarshal(rpcErr.Data, data) != nil || data.RetryAfter < 1 {
+		data.RetryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(data.RetryAfter))
+	return true
+}
+
+// withClient records the client address of requests for KeyByIP.
+func (s *Server) withClient(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next(w, s.withClientAddr(r))
+	})
+}
+
+// withStreamLimit records the client address and holds a stream slot for
+// stream-opening GET requests while the stream is open.
+func (s *Server) withStreamLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = s.withClientAddr(r)
+		if s.limiter == nil || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := s.limiter.limits.Key(r.Context())
+		if !s.limiter.openStream(key) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many open streams", http.StatusTooManyRequests)
+			return
+		}
+		defer s.limiter.closeStream(key)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+)
+
+func TestLimits(t *testing.T) {
+	var srv *Server
+	newOps := WithDefaultHandler(context.Background(), RegisterMessageSend(func(ctx context.Context, messages []schema.Message, contextID, taskID *string) (*schema.Task, *jsonrpc.Error) {
+		if taskID != nil {
+			task, _ := srv.tasks.get(*taskID)
+			return task, nil
+		}
+		return srv.tasks.newTask(contextID), nil
+	}))
+	srv = New(schema.AgentCard{Name: "test"}, WithOperations(newOps), WithLimits(Limits{
+		Methods:            map[string]Rate{"tasks/get": {PerSecond: 1, Burst: 2}},
+		MaxConcurrentTasks: 1,
+		MaxStreams:         1,
+	}))
+	now := time.Now()
+	srv.limiter.now = func() time.Time { return now }
+	mux := http.NewServeMux()
+	srv.RegisterJSONRPC(mux, "/rpc")
+	srv.RegisterREST(mux)
+	// callers are identified by X-User, standing in for the auth middleware
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user := r.Header.Get("X-User"); user != "" {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Subject: user}))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	call := func(user, method string, params interface{}) (*http.Response, rpcResp) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/rpc", bytes.NewReader(body))
+		req.Header.Set("X-User", user)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer resp.Body.Close()
+		var out rpcResp
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp, out
+	}
+	send := map[string]interface{}{"messages": []map[string]interface{}{{"role": "user", "parts": []map[string]interface{}{{"type": "text", "text": "hi"}}}}}
+	_, rpc := call("alice", "message/send", send)
+	var task schema.Task
+	if rpc.Error != nil || json.Unmarshal(rpc.Result, &task) != nil {
+		t.Fatalf("send: %+v", rpc.Error)
+	}
+
+	testCases := []struct {
+		description string
+		user        string
+		method      string
+		params      interface{}
+		status      int
+		retryAfter  string
+	}{
+		{description: "within burst", user: "alice", method: "tasks/get", params: map[string]string{"id": task.ID}, status: http.StatusOK},
+		{description: "burst used", user: "alice", method: "tasks/get", params: map[string]string{"id": task.ID}, status: http.StatusOK},
+		{description: "rate exceeded", user: "alice", method: "tasks/get", params: map[string]string{"id": task.ID}, status: http.StatusTooManyRequests, retryAfter: "1"},
+		{description: "other caller", user: "bob", method: "tasks/get", params: map[string]string{"id": task.ID}, status: http.StatusOK},
+		{description: "unlimited method", user: "alice", method: "tasks/pushNotificationConfig/list", params: map[string]string{"id": task.ID}, status: http.StatusOK},
+		{description: "running task cap", user: "alice", method: "message/send", params: send, status: http.StatusTooManyRequests, retryAfter: "1"},
+		{description: "continuing a running task", user: "alice", method: "message/send", params: map[string]interface{}{"taskId": task.ID, "messages": send["messages"]}, status: http.StatusOK},
+		{description: "other caller's task", user: "bob", method: "message/send", params: send, status: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		resp, rpc := call(testCase.user, testCase.method, testCase.params)
+		if resp.StatusCode != testCase.status || resp.Header.Get("Retry-After") != testCase.retryAfter {
+			t.Fatalf("%s: status=%d retry-after=%q error=%+v", testCase.description, resp.StatusCode, resp.Header.Get("Retry-After"), rpc.Error)
+		}
+		if testCase.status == http.StatusTooManyRequests && (rpc.Error == nil || rpc.Error.Code != codeRateLimited || string(rpc.Error.Data) != `{"retryAfter":`+testCase.retryAfter+`}`) {
+			t.Fatalf("%s: error=%+v", testCase.description, rpc.Error)
+		}
+	}
+
+	// REST routes share the buckets; tokens refill over time
+	get := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/tasks/"+task.ID, nil)
+		req.Header.Set("X-User", "alice")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := get(); resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+		t.Fatalf("rest limited: status=%d", resp.StatusCode)
+	}
+	now = now.Add(time.Second)
+	if resp := get(); resp.StatusCode != http.StatusOK {
+		t.Fatalf("rest refilled: status=%d", resp.StatusCode)
+	}
+
+	// finishing the task frees the slot
+	stored, _ := srv.tasks.get(task.ID)
+	stored.Touch(schema.TaskCompleted)
+	srv.tasks.put(stored)
+	if resp, rpc := call("alice", "message/send", send); resp.StatusCode != http.StatusOK || rpc.Error != nil {
+		t.Fatalf("send after completion: status=%d error=%+v", resp.StatusCode, rpc.Error)
+	}
+
+	// a caller holds at most MaxStreams open streams
+	release := make(chan struct{})
+	opened := make(chan struct{})
+	streams := httptest.NewServer(srv.withStreamLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		opened <- struct{}{}
+		<-release
+	})))
+	defer streams.Close()
+	go func() {
+		resp, err := http.Get(streams.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-opened
+	resp, err := http.Get(streams.URL)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second stream: err=%v", err)
+	}
+	resp.Body.Close()
+	stats := srv.Limiter().Stats()
+	close(release)
+	if stats.OpenStreams["ip:127.0.0.1"] != 1 || stats.Limited["stream"] != 1 || stats.Limited["tasks/get"] != 2 || stats.Limited["tasks"] != 1 || stats.ActiveTasks["principal:/alice"] != 1 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestLimitKeys(t *testing.T) {
+	srv := New(schema.AgentCard{Name: "test"}, WithTrustedProxies("10.0.0.0/8"))
+	testCases := []struct {
+		description string
+		remoteAddr  string
+		forwarded   []string
+		principal   *auth.Principal
+		expect      string
+	}{
+		{description: "subject", remoteAddr: "192.0.2.1:1234", principal: &auth.Principal{Tenant: "acme", Subject: "alice"}, expect: "principal:acme/alice"},
+		{description: "client_id without subject", remoteAddr: "192.0.2.1:1234", principal: &auth.Principal{Tenant: "acme", Claims: map[string]interface{}{"client_id": "billing-svc"}}, expect: "principal:acme/billing-svc"},
+		{description: "principal without identity", remoteAddr: "192.0.2.1:1234", principal: &auth.Principal{}, expect: "ip:192.0.2.1"},
+		{description: "anonymous", remoteAddr: "192.0.2.1:1234", expect: "ip:192.0.2.1"},
+		{description: "untrusted peer's forwarded header", remoteAddr: "192.0.2.1:1234", forwarded: []string{"198.51.100.7"}, expect: "ip:192.0.2.1"},
+		{description: "trusted proxy", remoteAddr: "10.0.0.5:1234", forwarded: []string{"198.51.100.7"}, expect: "ip:198.51.100.7"},
+		{description: "forged hops left of the client", remoteAddr: "10.0.0.5:1234", forwarded: []string{"203.0.113.9, 198.51.100.7", "10.0.0.6"}, expect: "ip:198.51.100.7"},
+		{description: "trusted proxy without forwarded header", remoteAddr: "10.0.0.5:1234", expect: "ip:10.0.0.5"},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+		r.RemoteAddr = testCase.remoteAddr
+		for _, value := range testCase.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if testCase.principal != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), testCase.principal))
+		}
+		if actual := KeyByPrincipal(srv.withClientAddr(r).Context()); actual != testCase.expect {
+			t.Fatalf("%s: got %q, want %q", testCase.description, actual, testCase.expect)
+		}
+	}
+}
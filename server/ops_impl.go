@@ -3,7 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
-	"strings"
+	"errors"
 	"time"
 
 	"github.com/viant/a2a-protocol/schema"
@@ -33,7 +33,8 @@ func (o *opsImpl) Implements(method string) bool {
 
 func (o *opsImpl) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
 
-// MessageSend creates/continues a task and may set auth-required state.
+// MessageSend creates/continues a task; a task still awaiting a requested
+// credential is returned in auth-required.
 func (o *opsImpl) MessageSend(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
 	var p struct {
 		ContextID *string          `json:"contextId,omitempty"`
@@ -44,25 +45,12 @@ func (o *opsImpl) MessageSend(ctx context.Context, request *jsonrpc.Request, res
 		response.Error = jsonrpc.NewInvalidParamsError("messages required", request.Params)
 		return
 	}
-	var task *schema.Task
-	if p.TaskID != nil && *p.TaskID != "" {
-		if existing, ok := o.srv.tasks.get(*p.TaskID); ok {
-			if isTerminal(existing.Status.State) {
-				response.Error = jsonrpc.NewError(-32006, "task is in terminal state", nil)
-				return
-			}
-			task = existing
-		}
-	}
-	if task == nil {
-		task = o.srv.tasks.newTask(p.ContextID)
+	task, waiting, jerr := o.continueTask(ctx, p.ContextID, p.TaskID, p.Messages, request.Params)
+	if jerr != nil {
+		response.Error = jerr
+		return
 	}
-
-	authReq := detectSecondaryAuth(p.Messages)
-	if authReq.Require && strings.TrimSpace(authReq.Token) == "" {
-		task.Touch(schema.TaskAuthRequired)
-		task.Status.Message = buildAuthMessage(authReq)
-		o.srv.tasks.put(task)
+	if waiting {
 		raw, _ := json.Marshal(task)
 		response.Result = raw
 		return
@@ -79,7 +67,7 @@ func (o *opsImpl) MessageSend(ctx context.Context, request *jsonrpc.Request, res
 
 // MessageStream starts streaming updates and returns the task immediately.
 func (o *opsImpl) MessageStream(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
-    if !o.srv.card.StreamingSupported() {
+    if !o.srv.streamingSupported() {
         response.Error = jsonrpc.NewError(-32002, "Streaming is not supported", nil)
         return
     }
@@ -92,25 +80,12 @@ func (o *opsImpl) MessageStream(ctx context.Context, request *jsonrpc.Request, r
 		response.Error = jsonrpc.NewInvalidParamsError("messages required", request.Params)
 		return
 	}
-	var task *schema.Task
-	if p.TaskID != nil && *p.TaskID != "" {
-		if existing, ok := o.srv.tasks.get(*p.TaskID); ok {
-			if isTerminal(existing.Status.State) {
-				response.Error = jsonrpc.NewError(-32006, "task is in terminal state", nil)
-				return
-			}
-			task = existing
-		}
-	}
-	if task == nil {
-		task = o.srv.tasks.newTask(p.ContextID)
+	task, waiting, jerr := o.continueTask(ctx, p.ContextID, p.TaskID, p.Messages, request.Params)
+	if jerr != nil {
+		response.Error = jerr
+		return
 	}
-
-	authReq := detectSecondaryAuth(p.Messages)
-	if authReq.Require && strings.TrimSpace(authReq.Token) == "" {
-		task.Touch(schema.TaskAuthRequired)
-		task.Status.Message = buildAuthMessage(authReq)
-		o.srv.tasks.put(task)
+	if waiting {
 		go func() { _ = o.sendStatus(context.Background(), task, false) }()
 		raw, _ := json.Marshal(task)
 		response.Result = raw
@@ -158,7 +133,7 @@ func (o *opsImpl) TasksCancel(_ context.Context, request *jsonrpc.Request, respo
 }
 
 func (o *opsImpl) TasksResubscribe(_ context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
-    if !o.srv.card.StreamingSupported() {
+    if !o.srv.streamingSupported() {
         response.Error = jsonrpc.NewError(-32002, "Streaming is not supported", nil)
         return
     }
@@ -175,14 +150,28 @@ func (o *opsImpl) TasksResubscribe(_ context.Context, request *jsonrpc.Request,
 	response.Result = b
 }
 
-func (o *opsImpl) AgentGetCard(_ context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
-	raw, _ := json.Marshal(o.srv.card)
-	response.Result = raw
+func (o *opsImpl) AgentGetCard(ctx context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	writeExtendedCard(ctx, o.srv, response)
 }
 
-func (o *opsImpl) pushSupported() bool { return o.srv.card.PushNotificationsSupported() }
+// writeExtendedCard resolves the authenticated extended card into response.
+func writeExtendedCard(ctx context.Context, srv *Server, response *jsonrpc.Response) {
+	card, err := srv.extendedCardFor(ctx)
+	switch {
+	case errors.Is(err, errExtendedCardNotConfigured):
+		response.Error = jsonrpc.NewError(-32007, err.Error(), nil)
+	case errors.Is(err, errUnauthenticated):
+		response.Error = jsonrpc.NewError(codeUnauthenticated, err.Error(), nil)
+	case err != nil:
+		response.Error = jsonrpc.NewError(-32603, "internal error", err.Error())
+	default:
+		response.Result, _ = json.Marshal(card)
+	}
+}
+
+func (o *opsImpl) pushSupported() bool { return o.srv.pushSupported() }
 
-func (o *opsImpl) TasksPushNotificationConfigSet(_ context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+func (o *opsImpl) TasksPushNotificationConfigSet(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
 	if !o.pushSupported() {
 		response.Error = jsonrpc.NewError(-32003, "Push Notification is not supported", nil)
 		return
@@ -195,12 +184,7 @@ func (o *opsImpl) TasksPushNotificationConfigSet(_ context.Context, request *jso
 		response.Error = jsonrpc.NewInvalidParamsError("taskId and config required", request.Params)
 		return
 	}
-	if cfg := o.srv.tasks.addPush(p.TaskID, &p.Config); cfg != nil {
-		raw, _ := json.Marshal(cfg)
-		response.Result = raw
-		return
-	}
-	response.Error = jsonrpc.NewError(-32004, "not found", nil)
+	writePushConfigSet(ctx, o.srv, p.TaskID, &p.Config, request.Params, response)
 }
 
 func (o *opsImpl) TasksPushNotificationConfigGet(_ context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
@@ -217,7 +201,7 @@ func (o *opsImpl) TasksPushNotificationConfigGet(_ context.Context, request *jso
         return
     }
 	if cfg, ok := o.srv.tasks.getPush(p.TaskID, p.ConfigID); ok {
-		raw, _ := json.Marshal(cfg)
+		raw, _ := json.Marshal(cfg.Redacted())
 		response.Result = raw
 		return
 	}
@@ -237,7 +221,7 @@ func (o *opsImpl) TasksPushNotificationConfigList(_ context.Context, request *js
 		return
 	}
 	if cfgs, ok := o.srv.tasks.listPush(p.TaskID); ok {
-		raw, _ := json.Marshal(cfgs)
+		raw, _ := json.Marshal(redactPush(cfgs))
 		response.Result = raw
 		return
 	}
@@ -288,64 +272,24 @@ func (o *opsImpl) sendArtifact(ctx context.Context, task *schema.Task, artifact
 	return sendSSEResponse(ctx, evt)
 }
 
-// secondary auth helpers (copied)
-type secAuth struct {
-	Require          bool
-	Resource         string
-	Scopes           []string
-	AuthorizationURI string
-	Token            string
-}
-
-func detectSecondaryAuth(messages []schema.Message) secAuth {
-	var out secAuth
-	for _, m := range messages {
-		for _, raw := range m.PartsRaw {
-			var probe map[string]interface{}
-			if err := json.Unmarshal(raw, &probe); err != nil {
-				continue
-			}
-			t, _ := probe["type"].(string)
-			if strings.ToLower(t) != "data" {
-				continue
-			}
-			data, _ := probe["data"].(map[string]interface{})
-			if data == nil {
-				continue
-			}
-			if v, ok := data["requireSecondaryAuth"].(bool); ok && v {
-				out.Require = true
-			}
-			if v, ok := data["resource"].(string); ok {
-				out.Resource = v
-			}
-			if v, ok := data["authorization_uri"].(string); ok {
-				out.AuthorizationURI = v
-			}
-			if v, ok := data["secondaryAuthToken"].(string); ok {
-				out.Token = v
-			}
-			if v, ok := data["scopes"].([]interface{}); ok {
-				for _, s := range v {
-					if sv, ok := s.(string); ok {
-						out.Scopes = append(out.Scopes, sv)
-					}
-				}
+// continueTask returns the task named by taskID, or a new one, after handing
+// the credentials carried by messages to the broker. waiting reports that the
+// task still awaits a requested credential.
+func (o *opsImpl) continueTask(ctx context.Context, contextID, taskID *string, messages []schema.Message, params []byte) (*schema.Task, bool, *jsonrpc.Error) {
+	var task *schema.Task
+	if taskID != nil && *taskID != "" {
+		if existing, ok := o.srv.tasks.get(*taskID); ok {
+			if isTerminal(existing.Status.State) {
+				return nil, false, jsonrpc.NewError(-32006, "task is in terminal state", nil)
 			}
+			task = existing
 		}
 	}
-	return out
-}
-
-func buildAuthMessage(a secAuth) *schema.DataPart {
-	payload := map[string]interface{}{
-		"auth": map[string]interface{}{
-			"resource": a.Resource,
-			"scopes":   a.Scopes,
-		},
+	if task == nil {
+		task = o.srv.newTask(ctx, contextID)
 	}
-	if a.AuthorizationURI != "" {
-		payload["auth"].(map[string]interface{})["authorization_uri"] = a.AuthorizationURI
+	if err := o.srv.supplyCredentials(ctx, task, messages); err != nil {
+		return nil, false, jsonrpc.NewInvalidParamsError(err.Error(), params)
 	}
-	return &schema.DataPart{Type: "data", Data: payload}
+	return task, task.Status.State == schema.TaskAuthRequired, nil
 }
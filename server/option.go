@@ -1,6 +1,7 @@
 package server
 
 import (
+	"github.com/viant/a2a-protocol/schema"
 	"github.com/viant/jsonrpc/transport"
 )
 
@@ -14,3 +15,61 @@ type ServerOption func(*Server)
 func WithOperations(factory NewOperationsFunc) ServerOption {
 	return func(s *Server) { s.opsFactory = factory }
 }
+
+// WithExtendedCard configures a static authenticated extended agent card.
+func WithExtendedCard(card schema.AgentCard) ServerOption {
+	return func(s *Server) {
+		s.extendedCard = staticCard(card)
+	}
+}
+
+// WithExtendedCardFunc configures a provider for the authenticated extended
+// agent card. The context carries the caller's authentication (see auth.FromContext),
+// so the provider can tailor skills or details per caller.
+func WithExtendedCardFunc(fn ExtendedCardFunc) ServerOption {
+	return func(s *Server) { s.extendedCard = fn }
+}
+
+// WithCardProvider sets a function that supplies the current public agent card
+// on every read, e.g. to derive it from external configuration. It must be
+// safe for concurrent use.
+func WithCardProvider(fn CardProviderFunc) ServerOption {
+	return func(s *Server) { s.cardProvider = fn }
+}
+
+// WithCardCacheControl sets the Cache-Control value used when serving agent cards.
+func WithCardCacheControl(value string) ServerOption {
+	return func(s *Server) { s.cardCacheControl = value }
+}
+
+// WithTrustedProxies lists the IP addresses or CIDR ranges of reverse proxies
+// whose X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix headers
+// are honored when resolving agent card URLs, and whose X-Forwarded-For
+// header gives the client IP used by KeyByIP. Other peers' headers are ignored.
+func WithTrustedProxies(proxies ...string) ServerOption {
+	return func(s *Server) { s.trustedProxies = proxies }
+}
+
+// Default JSON-RPC request bounds, used unless overridden by WithRequestLimits.
+const (
+	DefaultMaxRequestBytes  = 4 << 20
+	DefaultMaxBatchSize     = 100
+	DefaultBatchConcurrency = 8
+)
+
+// WithRequestLimits bounds JSON-RPC requests: the body size in bytes, the
+// number of calls in a batch and how many batch calls run at once. Zero keeps
+// the corresponding default.
+func WithRequestLimits(maxBytes int64, maxBatchSize, batchConcurrency int) ServerOption {
+	return func(s *Server) {
+		if maxBytes > 0 {
+			s.maxRequestBytes = maxBytes
+		}
+		if maxBatchSize > 0 {
+			s.maxBatchSize = maxBatchSize
+		}
+		if batchConcurrency > 0 {
+			s.batchConcurrency = batchConcurrency
+		}
+	}
+}
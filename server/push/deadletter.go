@@ -0,0 +1,56 @@
+package push
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter records a notification that could not be delivered.
+type DeadLetter struct {
+	TaskID     string          `json:"taskId"`
+	ConfigID   string          `json:"configId"`
+	URL        string          `json:"url"`
+	Event      string          `json:"event"`
+	Attempts   int             `json:"attempts"`
+	StatusCode int             `json:"statusCode,omitempty"`
+	Error      string          `json:"error"`
+	At         time.Time       `json:"at"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// DeadLetters returns the dead-letter log for a task's push notification config, oldest first.
+func (d *Dispatcher) DeadLetters(taskID, configID string) []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entries := d.deadLetters[deadLetterKey(taskID, configID)]
+	out := make([]DeadLetter, len(entries))
+	copy(out, entries)
+	return out
+}
+
+func (d *Dispatcher) deadLetter(item *delivery, attempts, status int, err error) {
+	entry := DeadLetter{
+		TaskID:     item.notification.TaskID,
+		ConfigID:   item.config.ID,
+		URL:        item.config.URL,
+		Event:      item.notification.Event(),
+		Attempts:   attempts,
+		StatusCode: status,
+		Error:      err.Error(),
+		At:         time.Now().UTC(),
+		Body:       item.notification.Body,
+	}
+	key := deadLetterKey(entry.TaskID, entry.ConfigID)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	summary := d.status(item)
+	summary.Failed++
+	summary.LastError = entry.Error
+	entries := append(d.deadLetters[key], entry)
+	if d.deadLetterLimit > 0 && len(entries) > d.deadLetterLimit {
+		entries = entries[len(entries)-d.deadLetterLimit:]
+	}
+	d.deadLetters[key] = entries
+}
+
+func deadLetterKey(taskID, configID string) string { return taskID + "/" + configID }
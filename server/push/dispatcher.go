@@ -0,0 +1,379 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/schema/notification"
+)
+
+// Notification is a single task change to deliver.
+type Notification struct {
+	TaskID string
+	// Body is the JSON-encoded task.
+	Body []byte
+	// StatusChanged and ArtifactsChanged describe what triggered the notification.
+	StatusChanged    bool
+	ArtifactsChanged bool
+	// Final is true when the task reached a terminal state.
+	Final bool
+}
+
+// Event returns the event kind reported to the receiver.
+func (n *Notification) Event() string {
+	if n.StatusChanged {
+		return notification.EventStatusUpdate
+	}
+	return notification.EventArtifactUpdate
+}
+
+// Option configures a Dispatcher.
+type Option func(d *Dispatcher)
+
+// WithWorkers sets the number of concurrent delivery workers.
+func WithWorkers(n int) Option {
+	return func(d *Dispatcher) { d.workers = n }
+}
+
+// WithQueueSize sets the capacity of the pending delivery queue.
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) { d.queueSize = n }
+}
+
+// WithMaxAttempts sets the number of delivery attempts before dead-lettering.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) { d.maxAttempts = n }
+}
+
+// WithBackoff sets the initial and maximum retry delay.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(d *Dispatcher) { d.initialBackoff, d.maxBackoff = initial, max }
+}
+
+// WithHTTPClient sets the client used to POST notifications. The client
+// replaces the default SSRF-guarded transport; use URLValidator.DialContext
+// in its transport to keep the per-connection address check.
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Dispatcher) { d.http = c }
+}
+
+// WithDeadLetterLimit bounds the dead-letter log kept per config.
+func WithDeadLetterLimit(n int) Option {
+	return func(d *Dispatcher) { d.deadLetterLimit = n }
+}
+
+// WithTrackedLimit bounds the number of task configs whose delivery history
+// and dead letters are kept; the oldest are forgotten first.
+func WithTrackedLimit(n int) Option {
+	return func(d *Dispatcher) { d.trackedLimit = n }
+}
+
+// WithURLValidator sets the policy applied to webhook URLs and connections.
+func WithURLValidator(v *URLValidator) Option {
+	return func(d *Dispatcher) { d.validator = v }
+}
+
+// WithOwnershipVerification requires a validation-token handshake (see
+// VerifyOwnership) before a webhook config is accepted.
+func WithOwnershipVerification(enabled bool) Option {
+	return func(d *Dispatcher) { d.verifyOwnership = enabled }
+}
+
+// Dispatcher delivers notifications to webhooks.
+type Dispatcher struct {
+	workers         int
+	queueSize       int
+	maxAttempts     int
+	initialBackoff  time.Duration
+	maxBackoff      time.Duration
+	deadLetterLimit int
+	historyLimit    int
+	trackedLimit    int
+	http            *http.Client
+	validator       *URLValidator
+	verifyOwnership bool
+	keys            *jwt.KeySet
+
+	startOnce sync.Once
+	// ready holds deliveries due for an attempt. Only the head of a task's
+	// pending list is ever ready, so its notifications are delivered in order.
+	ready  chan *delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu          sync.Mutex
+	deadLetters map[string][]DeadLetter
+	history     map[string]*DeliveryStatus
+	// pending lists the undelivered notifications of each task, oldest first;
+	// queued counts them across tasks for WithQueueSize.
+	pending map[string][]*delivery
+	queued  int
+	// tracked lists history keys, oldest first, for WithTrackedLimit.
+	tracked []string
+}
+
+type delivery struct {
+	config       schema.PushNotificationConfig
+	notification Notification
+	// attempts counts the attempts made so far.
+	attempts int
+}
+
+// New creates a Dispatcher. Workers start on the first Notify call.
+func New(options ...Option) *Dispatcher {
+	d := &Dispatcher{
+		workers:         4,
+		queueSize:       1024,
+		maxAttempts:     5,
+		initialBackoff:  500 * time.Millisecond,
+		maxBackoff:      30 * time.Second,
+		deadLetterLimit: 100,
+		historyLimit:    50,
+		trackedLimit:    10000,
+		validator:       &URLValidator{},
+		deadLetters:     map[string][]DeadLetter{},
+		history:         map[string]*DeliveryStatus{},
+		pending:         map[string][]*delivery{},
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	if d.workers < 1 {
+		d.workers = 1
+	}
+	if d.queueSize < 1 {
+		d.queueSize = 1
+	}
+	if d.http == nil {
+		d.http = guardedClient(d.validator)
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	return d
+}
+
+func (d *Dispatcher) start() {
+	// pending never exceeds queueSize, so sends to ready never block
+	d.ready = make(chan *delivery, d.queueSize)
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Notify queues n for delivery to config. It returns false (and dead-letters
+// the notification) when the queue is full or the dispatcher is closed.
+func (d *Dispatcher) Notify(config schema.PushNotificationConfig, n Notification) bool {
+	d.startOnce.Do(d.start)
+	item := &delivery{config: config, notification: n}
+	if d.ctx.Err() != nil {
+		d.deadLetter(item, 0, 0, fmt.Errorf("dispatcher closed"))
+		return false
+	}
+	d.mu.Lock()
+	if d.queued >= d.queueSize {
+		d.mu.Unlock()
+		d.deadLetter(item, 0, 0, fmt.Errorf("delivery queue full"))
+		return false
+	}
+	d.queued++
+	earlier := d.pending[n.TaskID]
+	d.pending[n.TaskID] = append(earlier, item)
+	d.mu.Unlock()
+	if len(earlier) == 0 {
+		d.ready <- item
+	}
+	return true
+}
+
+// Close stops the workers, abandoning retries in progress.
+func (d *Dispatcher) Close() {
+	d.startOnce.Do(d.start)
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case item := <-d.ready:
+			d.attempt(item)
+		}
+	}
+}
+
+// done removes item from its task's pending list and makes the task's next
+// notification ready.
+func (d *Dispatcher) done(item *delivery) {
+	taskID := item.notification.TaskID
+	d.mu.Lock()
+	d.queued--
+	rest := d.pending[taskID][1:]
+	if len(rest) == 0 {
+		delete(d.pending, taskID)
+	} else {
+		d.pending[taskID] = rest
+	}
+	d.mu.Unlock()
+	if len(rest) > 0 {
+		d.ready <- rest[0]
+	}
+}
+
+// configError marks a failure caused by the push config itself, such as an
+// unusable URL or authentication scheme, which no retry can fix.
+type configError struct{ err error }
+
+func (e *configError) Error() string { return e.err.Error() }
+
+func (e *configError) Unwrap() error { return e.err }
+
+// attempt makes the next delivery attempt of item. A retryable failure
+// schedules a retry after the backoff without holding the worker; the task's
+// later notifications wait until item is delivered or dead-lettered. Config
+// errors are dead-lettered without retrying.
+func (d *Dispatcher) attempt(item *delivery) {
+	if item.attempts == 0 {
+		if err := d.validator.Validate(d.ctx, item.config.URL); err != nil {
+			d.deadLetter(item, 0, 0, err)
+			d.done(item)
+			return
+		}
+	}
+	item.attempts++
+	started := time.Now()
+	status, err := d.post(item)
+	d.recordAttempt(item, item.attempts, started, status, err)
+	if err == nil {
+		d.done(item)
+		return
+	}
+	var cfgErr *configError
+	if errors.As(err, &cfgErr) || !retryable(status) || item.attempts >= d.maxAttempts {
+		d.deadLetter(item, item.attempts, status, err)
+		d.done(item)
+		return
+	}
+	wait := time.NewTimer(d.backoff(item.attempts))
+	go func() {
+		defer wait.Stop()
+		select {
+		case <-d.ctx.Done():
+			d.deadLetter(item, item.attempts, status, fmt.Errorf("dispatcher closed: %w", err))
+			d.done(item)
+		case <-wait.C:
+			d.ready <- item
+		}
+	}()
+}
+
+// post sends a single attempt, returning the HTTP status (0 on transport error).
+func (d *Dispatcher) post(item *delivery) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, item.config.URL, bytes.NewReader(item.notification.Body))
+	if err != nil {
+		return 0, &configError{err}
+	}
+	if err = d.authorize(req, item); err != nil {
+		return 0, &configError{err}
+	}
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// authorize sets content, token, signature and authentication headers.
+func (d *Dispatcher) authorize(req *http.Request, item *delivery) error {
+	cfg, n := &item.config, &item.notification
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(notification.HeaderEvent, n.Event())
+	if cfg.Token != nil && *cfg.Token != "" {
+		req.Header.Set(notification.HeaderNotificationToken, *cfg.Token)
+	}
+	now, nonce := time.Now(), newNonce()
+	if cfg.Secret != nil && *cfg.Secret != "" {
+		req.Header.Set(notification.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(notification.HeaderNonce, nonce)
+		req.Header.Set(notification.HeaderSignature, notification.Sign(*cfg.Secret, now, nonce, n.Body))
+	}
+	if d.keys != nil {
+		token, err := signJWT(d.keys, now, nonce, n)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(notification.HeaderJWT, token)
+	}
+	if auth := cfg.Authentication; auth != nil && auth.Credentials != nil {
+		for _, scheme := range auth.Schemes {
+			switch strings.ToLower(scheme) {
+			case "bearer":
+				req.Header.Set("Authorization", "Bearer "+*auth.Credentials)
+				return nil
+			case "basic":
+				req.Header.Set("Authorization", "Basic "+*auth.Credentials)
+				return nil
+			}
+		}
+		return fmt.Errorf("unsupported push authentication schemes: %v", auth.Schemes)
+	}
+	return nil
+}
+
+// guardedClient returns a client that connects only to addresses permitted by
+// v, ignores proxy settings and does not follow redirects.
+func guardedClient(v *URLValidator) *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:           v.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost:   2,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// backoff returns a full-jitter exponential delay for the given attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	ceiling := d.initialBackoff << uint(attempt-1)
+	if ceiling <= 0 || ceiling > d.maxBackoff {
+		ceiling = d.maxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// retryable reports whether a failed attempt with status should be retried;
+// status 0 is a transport error.
+func retryable(status int) bool {
+	switch {
+	case status == 0, status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return true
+	case status >= 500:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package push
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/schema/notification"
+)
+
+// loopback permits the plain-http httptest servers used below.
+var loopback = &URLValidator{AllowedHosts: []string{"127.0.0.1"}}
+
+func TestDispatcher_RetryAndSign(t *testing.T) {
+	var calls int32
+	received := make(chan *http.Request, 1)
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer ts.Close()
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keys, _ := jwt.NewKeySet(jwt.SigningKey{ID: "k1", Key: key})
+	d := New(WithBackoff(time.Millisecond, 5*time.Millisecond), WithWorkers(1), WithURLValidator(loopback), WithSigningKeys(keys))
+	defer d.Close()
+	token, secret, creds := "tok", "s3cret", "abc"
+	cfg := schema.PushNotificationConfig{
+		ID: "c1", URL: ts.URL, Token: &token, Secret: &secret,
+		Authentication: &schema.PushNotificationAuthenticationInfo{Schemes: []string{"Bearer"}, Credentials: &creds},
+	}
+	d.Notify(cfg, Notification{TaskID: "t-1", Body: []byte(`{"id":"t-1"}`), StatusChanged: true})
+
+	select {
+	case r := <-received:
+		if r.Header.Get(notification.HeaderNotificationToken) != token {
+			t.Fatalf("token header=%q", r.Header.Get(notification.HeaderNotificationToken))
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			t.Fatalf("authorization=%q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get(notification.HeaderEvent) != notification.EventStatusUpdate {
+			t.Fatalf("event=%q", r.Header.Get(notification.HeaderEvent))
+		}
+		if !notification.VerifySignature(secret, r.Header.Get(notification.HeaderSignature), r.Header.Get(notification.HeaderTimestamp), r.Header.Get(notification.HeaderNonce), body) {
+			t.Fatalf("signature did not verify")
+		}
+		claims, err := notification.VerifyJWT(context.Background(), r.Header.Get(notification.HeaderJWT), body, keys.KeyFunc(), time.Minute)
+		if err != nil || claims.TaskID != "t-1" {
+			t.Fatalf("jwt did not verify: %+v %v", claims, err)
+		}
+		if _, err = notification.VerifyJWT(context.Background(), r.Header.Get(notification.HeaderJWT), []byte(`{"id":"t-2"}`), keys.KeyFunc(), time.Minute); err == nil {
+			t.Fatalf("jwt should not verify a different body")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("notification not delivered, calls=%d", atomic.LoadInt32(&calls))
+	}
+	if len(d.DeadLetters("t-1", "c1")) != 0 {
+		t.Fatalf("unexpected dead letters")
+	}
+}
+
+func TestDispatcher_DeadLetter(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	d := New(WithBackoff(time.Millisecond, time.Millisecond), WithMaxAttempts(3), WithURLValidator(loopback))
+	defer d.Close()
+	d.Notify(schema.PushNotificationConfig{ID: "c1", URL: ts.URL}, Notification{TaskID: "t-1", Body: []byte(`{}`), Final: true})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(d.DeadLetters("t-1", "c1")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected dead letter")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	letter := d.DeadLetters("t-1", "c1")[0]
+	if letter.StatusCode != http.StatusBadRequest || letter.Attempts != 1 || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("non-retryable failure should dead-letter after one attempt: %+v calls=%d", letter, calls)
+	}
+
+	// a config error is dead-lettered without retrying or calling the webhook
+	creds := "abc"
+	d.Notify(schema.PushNotificationConfig{ID: "c2", URL: ts.URL, Authentication: &schema.PushNotificationAuthenticationInfo{Schemes: []string{"Digest"}, Credentials: &creds}}, Notification{TaskID: "t-2", Body: []byte(`{}`)})
+	for len(d.DeadLetters("t-2", "c2")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected config error dead letter")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if letter = d.DeadLetters("t-2", "c2")[0]; letter.Attempts != 1 || letter.StatusCode != 0 || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("config error: %+v calls=%d", letter, calls)
+	}
+
+	// only the most recently tracked configs are kept
+	bounded := New(WithTrackedLimit(2), WithURLValidator(loopback))
+	defer bounded.Close()
+	for _, taskID := range []string{"t-1", "t-2", "t-3"} {
+		bounded.deadLetter(&delivery{config: schema.PushNotificationConfig{ID: "c1"}, notification: Notification{TaskID: taskID}}, 0, 0, io.EOF)
+	}
+	if _, ok := bounded.Deliveries("t-1", "c1"); ok || len(bounded.DeadLetters("t-1", "c1")) != 0 || len(bounded.DeadLetters("t-3", "c1")) != 1 {
+		t.Fatalf("tracked=%v", bounded.tracked)
+	}
+}
+
+func TestDispatcher_Ordering(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "0" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	d := New(WithWorkers(8), WithURLValidator(loopback))
+	defer d.Close()
+	var expect []string
+	for i := 0; i < 20; i++ {
+		expect = append(expect, strconv.Itoa(i))
+		d.Notify(schema.PushNotificationConfig{ID: "c1", URL: ts.URL}, Notification{TaskID: "t-1", Body: []byte(expect[i])})
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		done := len(received) == len(expect)
+		mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("received=%v", received)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if strings.Join(received, ",") != strings.Join(expect, ",") {
+		t.Fatalf("notifications of one task delivered out of order: %v", received)
+	}
+}
+
+func TestDispatcher_RetryDoesNotBlockWorker(t *testing.T) {
+	var failing int32
+	delivered := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "down" {
+			atomic.AddInt32(&failing, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered <- string(body)
+	}))
+	defer ts.Close()
+
+	// a single worker: both tasks share it, and the first one backs off for minutes
+	d := New(WithWorkers(1), WithBackoff(time.Minute, time.Minute), WithURLValidator(loopback))
+	defer d.Close()
+	d.Notify(schema.PushNotificationConfig{ID: "c1", URL: ts.URL}, Notification{TaskID: "t-1", Body: []byte("down")})
+	d.Notify(schema.PushNotificationConfig{ID: "c1", URL: ts.URL}, Notification{TaskID: "t-1", Body: []byte("after")})
+	d.Notify(schema.PushNotificationConfig{ID: "c1", URL: ts.URL}, Notification{TaskID: "t-2", Body: []byte("other")})
+	select {
+	case body := <-delivered:
+		if body != "other" {
+			t.Fatalf("delivered %q before the failing notification of its task", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a retrying task blocked another task's delivery")
+	}
+	if atomic.LoadInt32(&failing) != 1 {
+		t.Fatalf("failing attempts=%d", failing)
+	}
+	select {
+	case body := <-delivered:
+		t.Fatalf("%q delivered while the task's earlier notification awaits a retry", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,5 @@
+// Package push delivers A2A push notifications to registered webhooks.
+// A Dispatcher POSTs the task to each webhook using a worker pool,
+// retries failed deliveries with exponential backoff and jitter, and keeps
+// a bounded dead-letter log per push notification config.
+package push
@@ -0,0 +1,95 @@
+package push
+
+import "time"
+
+// Attempt records a single delivery attempt.
+type Attempt struct {
+	At         time.Time `json:"at"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// DeliveryStatus summarizes deliveries to a task's push notification config.
+type DeliveryStatus struct {
+	TaskID   string `json:"taskId"`
+	ConfigID string `json:"configId"`
+	URL      string `json:"url"`
+	// Delivered and Failed count notifications, not attempts; Failed ones are dead-lettered.
+	Delivered      int        `json:"delivered"`
+	Failed         int        `json:"failed"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty"`
+	LastSuccessAt  *time.Time `json:"lastSuccessAt,omitempty"`
+	// Attempts lists the most recent attempts, oldest first.
+	Attempts []Attempt `json:"attempts"`
+}
+
+// WithHistoryLimit bounds the attempts kept per config.
+func WithHistoryLimit(n int) Option {
+	return func(d *Dispatcher) { d.historyLimit = n }
+}
+
+// Deliveries returns the delivery status of a task's push notification config.
+func (d *Dispatcher) Deliveries(taskID, configID string) (DeliveryStatus, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status, ok := d.history[deadLetterKey(taskID, configID)]
+	if !ok {
+		return DeliveryStatus{}, false
+	}
+	out := *status
+	out.Attempts = make([]Attempt, len(status.Attempts))
+	copy(out.Attempts, status.Attempts)
+	return out, true
+}
+
+// recordAttempt appends an attempt outcome to the config's history.
+func (d *Dispatcher) recordAttempt(item *delivery, attempt int, started time.Time, code int, err error) {
+	entry := Attempt{
+		At:         started.UTC(),
+		Event:      item.notification.Event(),
+		Attempt:    attempt,
+		StatusCode: code,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.status(item)
+	status.LastAttemptAt = &entry.At
+	status.LastStatusCode = code
+	status.LastError = entry.Error
+	if err == nil {
+		status.Delivered++
+		status.LastSuccessAt = &entry.At
+	}
+	status.Attempts = append(status.Attempts, entry)
+	if d.historyLimit > 0 && len(status.Attempts) > d.historyLimit {
+		status.Attempts = status.Attempts[len(status.Attempts)-d.historyLimit:]
+	}
+}
+
+// status returns the history entry for item, creating it; d.mu must be held.
+func (d *Dispatcher) status(item *delivery) *DeliveryStatus {
+	key := deadLetterKey(item.notification.TaskID, item.config.ID)
+	status, ok := d.history[key]
+	if !ok {
+		status = &DeliveryStatus{TaskID: item.notification.TaskID, ConfigID: item.config.ID, Attempts: []Attempt{}}
+		d.history[key] = status
+		d.tracked = append(d.tracked, key)
+		for d.trackedLimit > 0 && len(d.tracked) > d.trackedLimit {
+			oldest := d.tracked[0]
+			d.tracked = d.tracked[1:]
+			delete(d.history, oldest)
+			delete(d.deadLetters, oldest)
+		}
+	}
+	status.URL = item.config.URL
+	return status
+}
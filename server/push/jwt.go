@@ -0,0 +1,32 @@
+package push
+
+import (
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema/notification"
+)
+
+// jwtLifetime bounds the exp claim of notification tokens.
+const jwtLifetime = 5 * time.Minute
+
+// WithSigningKeys signs every notification with a JWT (notification.HeaderJWT)
+// from keys. Publish keys.JWKS() (the server does so at /.well-known/jwks.json)
+// so receivers can verify with notification.VerifyJWT.
+func WithSigningKeys(keys *jwt.KeySet) Option {
+	return func(d *Dispatcher) { d.keys = keys }
+}
+
+// Keys returns the signing key set, or nil when notifications are not signed.
+func (d *Dispatcher) Keys() *jwt.KeySet { return d.keys }
+
+// signJWT returns the notification token for body.
+func signJWT(keys *jwt.KeySet, now time.Time, nonce string, n *Notification) (string, error) {
+	return keys.Sign(jwt.Claims{
+		"iat":                      now.Unix(),
+		"exp":                      now.Add(jwtLifetime).Unix(),
+		"jti":                      nonce,
+		notification.ClaimTaskID:   n.TaskID,
+		notification.ClaimBodyHash: notification.BodyHash(n.Body),
+	})
+}
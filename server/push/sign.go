@@ -0,0 +1,12 @@
+package push
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+func newNonce() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
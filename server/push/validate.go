@@ -0,0 +1,151 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// blockedNetworks lists special-purpose ranges not covered by the net.IP predicates.
+var blockedNetworks = mustCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"64:ff9b::/96",  // NAT64
+)
+
+// URLValidator guards webhook URLs against server-side request forgery. It
+// requires https, resolves the host and rejects loopback, private, link-local
+// and other internal addresses. DialContext re-applies the address check on
+// every connection so DNS rebinding cannot redirect a validated host.
+type URLValidator struct {
+	// AllowedHosts are exempt from the https requirement and address checks.
+	// Entries match the URL host exactly or, with a "*." prefix, any subdomain.
+	AllowedHosts []string
+	// AllowedNetworks are address ranges exempt from the internal-address block.
+	AllowedNetworks []*net.IPNet
+	// Resolver is used for DNS lookups; net.DefaultResolver when nil.
+	Resolver *net.Resolver
+}
+
+// Validate checks the scheme and resolved addresses of rawURL.
+func (v *URLValidator) Validate(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	host := u.Hostname()
+	if host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("invalid webhook url: %q", rawURL)
+	}
+	if v.hostAllowed(host) {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use https: %q", rawURL)
+	}
+	_, err = v.resolve(ctx, host)
+	return err
+}
+
+// CheckIP returns an error when ip is an internal address not explicitly allowed.
+func (v *URLValidator) CheckIP(ip net.IP) error {
+	for _, network := range v.AllowedNetworks {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("webhook address %s is not allowed", ip)
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("webhook address %s is not allowed", ip)
+		}
+	}
+	return nil
+}
+
+// DialContext resolves addr, checks every address and connects to a
+// validated one, pinning the connection to the checked IP.
+func (v *URLValidator) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if v.hostAllowed(host) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	ips, err := v.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// resolve looks up host and rejects it if any address is internal.
+func (v *URLValidator) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, v.CheckIP(ip)
+	}
+	resolver := v.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve webhook host %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("webhook host %s has no addresses", host)
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if err := v.CheckIP(addr.IP); err != nil {
+			return nil, err
+		}
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+func (v *URLValidator) hostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range v.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if allowed == host {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustCIDRs(cidrs ...string) []*net.IPNet {
+	out := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, network)
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package push
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/viant/a2a-protocol/schema/notification"
+)
+
+func TestURLValidator_Validate(t *testing.T) {
+	_, corp, _ := net.ParseCIDR("10.1.0.0/16")
+	validator := &URLValidator{AllowedHosts: []string{"hooks.internal", "*.dev.local"}, AllowedNetworks: []*net.IPNet{corp}}
+	testCases := []struct {
+		description string
+		url         string
+		valid       bool
+	}{
+		{description: "public https address", url: "https://93.184.216.34/hook", valid: true},
+		{description: "plain http rejected", url: "http://93.184.216.34/hook"},
+		{description: "loopback rejected", url: "https://127.0.0.1/hook"},
+		{description: "ipv6 loopback rejected", url: "https://[::1]/hook"},
+		{description: "private range rejected", url: "https://192.168.1.10/hook"},
+		{description: "link-local metadata rejected", url: "https://169.254.169.254/latest"},
+		{description: "carrier-grade NAT rejected", url: "https://100.64.0.1/hook"},
+		{description: "ipv4-mapped loopback rejected", url: "https://[::ffff:127.0.0.1]/hook"},
+		{description: "allowed network", url: "https://10.1.2.3/hook", valid: true},
+		{description: "other private network", url: "https://10.2.0.1/hook"},
+		{description: "allowed host over http", url: "http://hooks.internal:8080/hook", valid: true},
+		{description: "allowed wildcard host", url: "http://a.dev.local/hook", valid: true},
+		{description: "unsupported scheme", url: "ftp://93.184.216.34/hook"},
+		{description: "missing host", url: "https:///hook"},
+	}
+	for _, testCase := range testCases {
+		err := validator.Validate(context.Background(), testCase.url)
+		if (err == nil) != testCase.valid {
+			t.Errorf("%s: %s valid=%v err=%v", testCase.description, testCase.url, testCase.valid, err)
+		}
+	}
+}
+
+func TestURLValidator_DialBlocksInternal(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	validator := &URLValidator{}
+	if _, err := validator.DialContext(context.Background(), "tcp", ts.Listener.Addr().String()); err == nil {
+		t.Fatalf("expected dial to loopback to be blocked")
+	}
+	conn, err := loopback.DialContext(context.Background(), "tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("allowed host dial failed: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDispatcher_VerifyOwnership(t *testing.T) {
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Query().Get(notification.ValidationTokenParam)))
+	}))
+	defer echo.Close()
+	silent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer silent.Close()
+
+	d := New(WithURLValidator(loopback), WithOwnershipVerification(true))
+	defer d.Close()
+	if err := d.VerifyOwnership(context.Background(), echo.URL+"/hook"); err != nil {
+		t.Fatalf("echoing receiver should verify: %v", err)
+	}
+	if err := d.VerifyOwnership(context.Background(), silent.URL); err == nil {
+		t.Fatalf("receiver not echoing the token should fail verification")
+	}
+}
@@ -0,0 +1,56 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/viant/a2a-protocol/schema/notification"
+)
+
+// verifyTimeout bounds the ownership handshake.
+const verifyTimeout = 10 * time.Second
+
+// ValidateURL checks rawURL against the dispatcher's URL policy.
+func (d *Dispatcher) ValidateURL(ctx context.Context, rawURL string) error {
+	return d.validator.Validate(ctx, rawURL)
+}
+
+// VerifyOwnership performs the validation-token handshake when enabled with
+// WithOwnershipVerification: it sends GET <url>?validationToken=<token> and
+// expects a 200 response whose body is exactly the token. It is a no-op when
+// verification is disabled.
+func (d *Dispatcher) VerifyOwnership(ctx context.Context, rawURL string) error {
+	if !d.verifyOwnership {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	token := newNonce()
+	query := u.Query()
+	query.Set(notification.ValidationTokenParam, token)
+	u.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook ownership verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(bytes.TrimSpace(body), []byte(token)) {
+		return fmt.Errorf("webhook ownership verification failed: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/push"
+	"github.com/viant/jsonrpc"
+)
+
+// WithPushDispatcher sets the dispatcher delivering push notifications.
+// By default a push.New() dispatcher is used.
+func WithPushDispatcher(d *push.Dispatcher) ServerOption {
+	return func(s *Server) { s.pushDispatcher = d }
+}
+
+// PushDeadLetters returns notifications that could not be delivered to a
+// task's push notification config.
+func (s *Server) PushDeadLetters(taskID, configID string) []push.DeadLetter {
+	return s.pushDispatcher.DeadLetters(taskID, configID)
+}
+
+// PushDeliveries returns recent delivery attempts, response codes and the
+// last error for a task's push notification config.
+func (s *Server) PushDeliveries(taskID, configID string) (push.DeliveryStatus, bool) {
+	if _, ok := s.tasks.getPush(taskID, configID); !ok {
+		return push.DeliveryStatus{}, false
+	}
+	if status, ok := s.pushDispatcher.Deliveries(taskID, configID); ok {
+		return status, true
+	}
+	return push.DeliveryStatus{TaskID: taskID, ConfigID: configID, Attempts: []push.Attempt{}}, true
+}
+
+// pushDeliveriesMethod names GET /v1/tasks/{id}/pushNotificationConfigs/{configId}/deliveries
+// in the interceptor chain; it is not exposed as a JSON-RPC method.
+const pushDeliveriesMethod = "tasks/pushNotificationConfig/deliveries"
+
+// pushDeliveries returns the delivery status of params.taskId and params.configId.
+func (s *Server) pushDeliveries(_ context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+	var p struct {
+		TaskID   string `json:"taskId"`
+		ConfigID string `json:"configId"`
+	}
+	if !s.pushSupported() {
+		response.Error = jsonrpc.NewError(-32003, "Push Notification is not supported", nil)
+		return
+	}
+	if err := json.Unmarshal(request.Params, &p); err != nil || p.TaskID == "" || p.ConfigID == "" {
+		response.Error = jsonrpc.NewInvalidParamsError("taskId and configId required", request.Params)
+		return
+	}
+	status, ok := s.PushDeliveries(p.TaskID, p.ConfigID)
+	if !ok {
+		response.Error = taskNotFound()
+		return
+	}
+	response.Result, _ = json.Marshal(status)
+}
+
+// writePushConfigSet validates the webhook URL, performs the optional
+// ownership handshake and registers cfg, writing the result or error to resp.
+func writePushConfigSet(ctx context.Context, s *Server, taskID string, cfg *schema.PushNotificationConfig, params []byte, resp *jsonrpc.Response) {
+	if _, ok := s.tasks.get(taskID); !ok {
+		resp.Error = jsonrpc.NewError(-32004, "not found", nil)
+		return
+	}
+	if err := cfg.ValidateEvents(); err != nil {
+		resp.Error = jsonrpc.NewInvalidParamsError(err.Error(), params)
+		return
+	}
+	if err := s.pushDispatcher.ValidateURL(ctx, cfg.URL); err != nil {
+		resp.Error = jsonrpc.NewInvalidParamsError(err.Error(), params)
+		return
+	}
+	if err := s.pushDispatcher.VerifyOwnership(ctx, cfg.URL); err != nil {
+		resp.Error = jsonrpc.NewInvalidParamsError(err.Error(), params)
+		return
+	}
+	if created := s.tasks.addPush(taskID, cfg); created != nil {
+		resp.Result, _ = json.Marshal(created.Redacted())
+		return
+	}
+	resp.Error = jsonrpc.NewError(-32004, "not found", nil)
+}
+
+// onTaskUpdate is called by the task store after a change is observed. Once
+// the task is terminal it releases the task's credentials and its limiter
+// slot; every change is then passed on to notifyPush.
+func (s *Server) onTaskUpdate(task *schema.Task, statusChanged, artifactsChanged bool) {
+	if isTerminal(task.Status.State) {
+		s.credentials.Release(task.ID)
+		if s.limiter != nil {
+			s.limiter.taskDone(task.ID)
+		}
+	}
+	s.notifyPush(task, statusChanged, artifactsChanged)
+}
+
+// notifyPush queues the task for delivery to every registered webhook whose
+// config accepts the kind of change.
+func (s *Server) notifyPush(task *schema.Task, statusChanged, artifactsChanged bool) {
+	if !s.pushSupported() {
+		return
+	}
+	cfgs, ok := s.tasks.listPush(task.ID)
+	if !ok || len(cfgs) == 0 {
+		return
+	}
+	body, err := json.Marshal(task)
+	if err != nil {
+		return
+	}
+	n := push.Notification{
+		TaskID:           task.ID,
+		Body:             body,
+		StatusChanged:    statusChanged,
+		ArtifactsChanged: artifactsChanged,
+		Final:            isTerminal(task.Status.State),
+	}
+	for _, cfg := range cfgs {
+		if cfg.Accepts(statusChanged, artifactsChanged, n.Final) {
+			s.pushDispatcher.Notify(*cfg, n)
+		}
+	}
+}
+
+// redactPush strips secrets from configs returned to callers.
+func redactPush(cfgs []*schema.PushNotificationConfig) []*schema.PushNotificationConfig {
+	out := make([]*schema.PushNotificationConfig, len(cfgs))
+	for i, cfg := range cfgs {
+		out[i] = cfg.Redacted()
+	}
+	return out
+}
@@ -5,11 +5,11 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/jsonrpc"
 )
 
 func (s *Server) handleSendMessageREST(w http.ResponseWriter, r *http.Request) {
-	// Delegate to rpcSendMessage-style handler using same payload
+	// Delegate to the message/send JSON-RPC method using the same payload
 	var req rpcRequest
 	req.ID = []byte("null")
 	raw, _ := json.Marshal(struct {
@@ -23,7 +23,7 @@ func (s *Server) handleSendMessageREST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Params = &params
-	s.rpcSendMessage(w, req)
+	s.dispatchRPC(r.Context(), w, req)
 }
 
 func (s *Server) handleGetTaskREST(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +34,7 @@ func (s *Server) handleGetTaskREST(w http.ResponseWriter, r *http.Request) {
 	}
 	var params json.RawMessage
 	_ = json.Unmarshal([]byte(`{"id":"`+id+`"}`), &params)
-	s.rpcGetTask(w, rpcRequest{JSONRPC: "2.0", ID: []byte("null"), Method: "tasks/get", Params: &params})
+	s.dispatchRPC(r.Context(), w, rpcRequest{JSONRPC: "2.0", ID: []byte("null"), Method: "tasks/get", Params: &params})
 }
 
 func (s *Server) handleTaskActionREST(w http.ResponseWriter, r *http.Request) {
@@ -61,7 +61,7 @@ func (s *Server) handleTaskActionREST(w http.ResponseWriter, r *http.Request) {
 		}
 		var params json.RawMessage
 		_ = json.Unmarshal([]byte(`{"id":"`+id+`"}`), &params)
-		s.rpcCancelTask(w, rpcRequest{JSONRPC: "2.0", ID: []byte("null"), Method: "tasks/cancel", Params: &params})
+		s.dispatchRPC(r.Context(), w, rpcRequest{JSONRPC: "2.0", ID: []byte("null"), Method: "tasks/cancel", Params: &params})
 		return
 	}
 	http.NotFound(w, r)
@@ -85,23 +85,18 @@ func (s *Server) handleTaskSubscribeREST(w http.ResponseWriter, r *http.Request)
 	}
 	var params json.RawMessage
 	_ = json.Unmarshal([]byte(`{"id":"`+id+`"}`), &params)
-	s.rpcResubscribe(w, rpcRequest{JSONRPC: "2.0", ID: []byte("null"), Method: "tasks/resubscribe", Params: &params})
+	s.dispatchRPC(r.Context(), w, rpcRequest{JSONRPC: "2.0", ID: []byte("null"), Method: "tasks/resubscribe", Params: &params})
 }
 
-// List tasks: GET /v1/tasks
+// List tasks: GET /v1/tasks, limited to the tasks the caller may access
 func (s *Server) handleListTasksREST(w http.ResponseWriter, r *http.Request) {
-	tasks := s.tasks.listTasks()
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(tasks)
+	response := s.callREST(r, listTasksMethod, nil)
+	writeRESTResult(w, response, http.StatusOK)
 }
 
 // Push notifications CRUD
 // POST /v1/tasks/{id}/pushNotificationConfigs
 func (s *Server) handleCreatePushConfigREST(w http.ResponseWriter, r *http.Request) {
-    if !s.card.PushNotificationsSupported() {
-        http.Error(w, "Push Notification is not supported", http.StatusNotImplemented)
-        return
-    }
 	// Extract task id
 	full := r.URL.Path
 	prefix := "/v1/tasks/"
@@ -117,46 +112,28 @@ func (s *Server) handleCreatePushConfigREST(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	taskID := rest[:idEnd]
-	var cfg schema.PushNotificationConfig
+	var cfg json.RawMessage
 	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	created := s.tasks.addPush(taskID, &cfg)
-	if created == nil {
-		http.Error(w, "task not found", http.StatusNotFound)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(created)
+	response := s.callREST(r, "tasks/pushNotificationConfig/set", map[string]interface{}{"taskId": taskID, "config": cfg})
+	writeRESTResult(w, response, http.StatusOK)
 }
 
 // GET /v1/tasks/{id}/pushNotificationConfigs/{configId}
 func (s *Server) handleGetPushConfigREST(w http.ResponseWriter, r *http.Request) {
-    if !s.card.PushNotificationsSupported() {
-        http.Error(w, "Push Notification is not supported", http.StatusNotImplemented)
-        return
-    }
 	taskID, cfgID := extractTaskAndConfigID(r.URL.Path)
 	if taskID == "" || cfgID == "" {
 		http.NotFound(w, r)
 		return
 	}
-	cfg, ok := s.tasks.getPush(taskID, cfgID)
-	if !ok {
-		http.Error(w, "not found", http.StatusNotFound)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(cfg)
+	response := s.callREST(r, "tasks/pushNotificationConfig/get", map[string]string{"taskId": taskID, "configId": cfgID})
+	writeRESTResult(w, response, http.StatusOK)
 }
 
 // GET /v1/tasks/{id}/pushNotificationConfigs
 func (s *Server) handleListPushConfigsREST(w http.ResponseWriter, r *http.Request) {
-    if !s.card.PushNotificationsSupported() {
-        http.Error(w, "Push Notification is not supported", http.StatusNotImplemented)
-        return
-    }
 	full := r.URL.Path
 	prefix := "/v1/tasks/"
 	pos := strings.Index(full, prefix)
@@ -171,31 +148,86 @@ func (s *Server) handleListPushConfigsREST(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	taskID := rest[:idEnd]
-	cfgs, ok := s.tasks.listPush(taskID)
-	if !ok {
-		http.Error(w, "task not found", http.StatusNotFound)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(cfgs)
+	response := s.callREST(r, "tasks/pushNotificationConfig/list", map[string]string{"taskId": taskID})
+	writeRESTResult(w, response, http.StatusOK)
 }
 
 // DELETE /v1/tasks/{id}/pushNotificationConfigs/{configId}
 func (s *Server) handleDeletePushConfigREST(w http.ResponseWriter, r *http.Request) {
-    if !s.card.PushNotificationsSupported() {
-        http.Error(w, "Push Notification is not supported", http.StatusNotImplemented)
-        return
-    }
 	taskID, cfgID := extractTaskAndConfigID(r.URL.Path)
 	if taskID == "" || cfgID == "" {
 		http.NotFound(w, r)
 		return
 	}
-	if !s.tasks.deletePush(taskID, cfgID) {
-		http.Error(w, "not found", http.StatusNotFound)
+	response := s.callREST(r, "tasks/pushNotificationConfig/delete", map[string]string{"taskId": taskID, "configId": cfgID})
+	if response.Error == nil {
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	writeRESTResult(w, response, http.StatusNoContent)
+}
+
+// GET /v1/tasks/{id}/pushNotificationConfigs/{configId}/deliveries
+func (s *Server) handlePushDeliveriesREST(w http.ResponseWriter, r *http.Request) {
+	taskID, cfgID := extractTaskAndConfigID(strings.TrimSuffix(r.URL.Path, "/deliveries"))
+	if taskID == "" || cfgID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	response := s.callREST(r, pushDeliveriesMethod, map[string]string{"taskId": taskID, "configId": cfgID})
+	writeRESTResult(w, response, http.StatusOK)
+}
+
+// callREST invokes an A2A method on behalf of a REST route through the
+// server's Operations and interceptor chain.
+func (s *Server) callREST(r *http.Request, method string, params interface{}) *jsonrpc.Response {
+	raw, _ := json.Marshal(params)
+	request := &jsonrpc.Request{Jsonrpc: jsonrpc.Version, Method: method, Params: raw}
+	response := &jsonrpc.Response{}
+	// REST-only methods bypass Operations but not the interceptors
+	switch method {
+	case listTasksMethod:
+		chain(s.interceptors, s.listTasks)(r.Context(), request, response)
+		return response
+	case pushDeliveriesMethod:
+		chain(s.interceptors, s.pushDeliveries)(r.Context(), request, response)
+		return response
+	}
+	s.invoke(r.Context(), s.operations(), request, response)
+	return response
+}
+
+// writeRESTResult renders a method result as plain JSON, mapping JSON-RPC
+// errors to HTTP status codes.
+func writeRESTResult(w http.ResponseWriter, response *jsonrpc.Response, status int) {
+	if response.Error != nil {
+		challengeInsufficientScope(w, response.Error)
+		retryAfter(w, response.Error)
+		http.Error(w, response.Error.Message, restStatus(response.Error.Code))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(response.Result)
+}
+
+// restStatus maps A2A JSON-RPC error codes to HTTP status codes.
+func restStatus(code int) int {
+	switch code {
+	case -32700, -32600, -32602:
+		return http.StatusBadRequest
+	case -32601, -32001, -32004, -32007:
+		return http.StatusNotFound
+	case -32002, -32003:
+		return http.StatusNotImplemented
+	case codeUnauthenticated:
+		return http.StatusUnauthorized
+	case codeInsufficientScope:
+		return http.StatusForbidden
+	case codeRateLimited:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
 }
 
 func extractTaskAndConfigID(path string) (taskID, cfgID string) {
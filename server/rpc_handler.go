@@ -2,55 +2,132 @@ package server
 
 import (
 	"context"
+	"time"
+
+	"github.com/viant/a2a-protocol/server/auth"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
 )
 
 // a2aHandler adapts Operations to a jsonrpc transport.Handler.
-type a2aHandler struct{ ops Operations }
+type a2aHandler struct {
+	srv *Server
+	ops Operations
+	// principal authenticated when the SSE/streamable session was created.
+	principal *auth.Principal
+	// clientAddr is the address the session was created from.
+	clientAddr string
+}
 
 func (h *a2aHandler) Serve(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
 	response.Id = request.Id
 	response.Jsonrpc = jsonrpc.Version
-	switch request.Method {
-	case "message/send":
-		h.ops.MessageSend(ctx, request, response)
-	case "message/stream":
-		h.ops.MessageStream(ctx, request, response)
-	case "tasks/get":
-		h.ops.TasksGet(ctx, request, response)
-	case "tasks/cancel":
-		h.ops.TasksCancel(ctx, request, response)
-	case "tasks/resubscribe":
-		h.ops.TasksResubscribe(ctx, request, response)
-	case "tasks/pushNotificationConfig/set":
-		h.ops.TasksPushNotificationConfigSet(ctx, request, response)
-	case "tasks/pushNotificationConfig/get":
-		h.ops.TasksPushNotificationConfigGet(ctx, request, response)
-	case "tasks/pushNotificationConfig/list":
-		h.ops.TasksPushNotificationConfigList(ctx, request, response)
-	case "tasks/pushNotificationConfig/delete":
-		h.ops.TasksPushNotificationConfigDelete(ctx, request, response)
-	case "agent/getAuthenticatedExtendedCard":
-		h.ops.AgentGetCard(ctx, request, response)
-	default:
-		response.Error = jsonrpc.NewMethodNotFound("method not found", nil)
+	ctx, rpcErr := h.context(ctx)
+	if rpcErr != nil {
+		response.Error = rpcErr
+		return
 	}
+	h.srv.invoke(ctx, h.ops, request, response)
 }
 
 func (h *a2aHandler) OnNotification(ctx context.Context, n *jsonrpc.Notification) {
-	h.ops.OnNotification(ctx, n)
+	if ctx, rpcErr := h.context(ctx); rpcErr == nil {
+		h.ops.OnNotification(ctx, n)
+	}
+}
+
+// context attaches the principal and client address a session message is
+// handled with. The session belongs to the identity that created it: the
+// principal of its latest admitted request is used, and a principal
+// authenticated on the message itself must share the creator's identity.
+// Expired credentials are rejected.
+func (h *a2aHandler) context(ctx context.Context) (context.Context, *jsonrpc.Error) {
+	if _, ok := ctx.Value(clientAddrKey{}).(string); !ok && h.clientAddr != "" {
+		ctx = context.WithValue(ctx, clientAddrKey{}, h.clientAddr)
+	}
+	principal := h.principal
+	if id := contextSession(ctx); id != "" {
+		latest, ok := h.srv.sessions.claim(id, h.principal)
+		if !ok {
+			return ctx, sessionNotFound()
+		}
+		principal = latest
+	}
+	if current, ok := auth.PrincipalFromContext(ctx); ok {
+		if identityOf(current) != identityOf(h.principal) {
+			return ctx, sessionNotFound()
+		}
+		principal = current
+	}
+	if principal == nil {
+		return ctx, nil
+	}
+	if expired(principal, time.Now()) {
+		return ctx, jsonrpc.NewError(-32600, "session credentials expired", nil)
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// sessionNotFound answers messages for a session owned by another principal.
+func sessionNotFound() *jsonrpc.Error {
+	return jsonrpc.NewError(-32600, "session not found", nil)
+}
+
+// dispatch routes a JSON-RPC request to the matching Operations method.
+func dispatch(ops Operations) MethodHandler {
+	return func(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+		switch request.Method {
+		case "message/send":
+			ops.MessageSend(ctx, request, response)
+		case "message/stream":
+			ops.MessageStream(ctx, request, response)
+		case "tasks/get":
+			ops.TasksGet(ctx, request, response)
+		case "tasks/cancel":
+			ops.TasksCancel(ctx, request, response)
+		case "tasks/resubscribe":
+			ops.TasksResubscribe(ctx, request, response)
+		case "tasks/pushNotificationConfig/set":
+			ops.TasksPushNotificationConfigSet(ctx, request, response)
+		case "tasks/pushNotificationConfig/get":
+			ops.TasksPushNotificationConfigGet(ctx, request, response)
+		case "tasks/pushNotificationConfig/list":
+			ops.TasksPushNotificationConfigList(ctx, request, response)
+		case "tasks/pushNotificationConfig/delete":
+			ops.TasksPushNotificationConfigDelete(ctx, request, response)
+		case "agent/getAuthenticatedExtendedCard":
+			ops.AgentGetCard(ctx, request, response)
+		default:
+			response.Error = jsonrpc.NewMethodNotFound("method not found", nil)
+		}
+	}
+}
+
+// invoke runs request through the configured interceptors and ops.
+func (s *Server) invoke(ctx context.Context, ops Operations, request *jsonrpc.Request, response *jsonrpc.Response) {
+	chain(s.interceptors, dispatch(ops))(ctx, request, response)
+}
+
+// newOperations builds Operations using the configured factory, if any.
+func (s *Server) newOperations(t transport.Transport) Operations {
+	if s.opsFactory != nil {
+		return s.opsFactory(s, t)
+	}
+	return NewOperations(s, t)
+}
+
+// operations returns the Operations serving plain HTTP JSON-RPC and REST routes.
+func (s *Server) operations() Operations {
+	s.opsOnce.Do(func() { s.ops = s.newOperations(nil) })
+	return s.ops
 }
 
 // newA2AHandler constructs a transport-backed handler with Operations.
 func newA2AHandler(srv *Server) transport.NewHandler {
 	return func(ctx context.Context, t transport.Transport) transport.Handler {
-		var ops Operations
-		if srv.opsFactory != nil {
-			ops = srv.opsFactory(srv, t)
-		} else {
-			ops = NewOperations(srv, t)
-		}
-		return &a2aHandler{ops: ops}
+		h := &a2aHandler{srv: srv, ops: srv.newOperations(t)}
+		h.principal, _ = auth.PrincipalFromContext(ctx)
+		h.clientAddr, _ = ctx.Value(clientAddrKey{}).(string)
+		return h
 	}
 }
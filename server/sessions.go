@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/server/auth"
+	"github.com/viant/jsonrpc"
+	base "github.com/viant/jsonrpc/transport/server/base"
+)
+
+// Streamable HTTP requests carry their session in sessionHeader, SSE message
+// requests in the sessionParam query parameter.
+const (
+	sessionHeader = "Mcp-Session-Id"
+	sessionParam  = "session_id"
+)
+
+// sessionIdleTimeout bounds how long an unused session binding is kept.
+const sessionIdleTimeout = time.Hour
+
+// sessionIdentity is who a session belongs to. Anonymous callers and
+// authenticated callers without an identity never share a session.
+type sessionIdentity struct {
+	owner         TaskOwner
+	authenticated bool
+}
+
+func identityOf(principal *auth.Principal) sessionIdentity {
+	owner, _, _ := ownerOf(principal)
+	return sessionIdentity{owner: owner, authenticated: principal != nil}
+}
+
+// sessionBinding ties a session to the identity that opened it and keeps the
+// principal authenticated on its latest request.
+type sessionBinding struct {
+	identity  sessionIdentity
+	principal *auth.Principal
+	lastSeen  time.Time
+}
+
+// sessionRegistry binds SSE and Streamable HTTP sessions to their principal.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	bindings map[string]*sessionBinding
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{now: time.Now, bindings: map[string]*sessionBinding{}}
+}
+
+// admit checks the principal of a request to session id. A session seen for
+// the first time is bound to the principal; later requests must come from
+// the same identity and refresh the principal used to handle the session.
+func (r *sessionRegistry) admit(id string, principal *auth.Principal) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	binding, ok := r.bindings[id]
+	if !ok {
+		r.sweep(now)
+		r.bindings[id] = &sessionBinding{identity: identityOf(principal), principal: principal, lastSeen: now}
+		return true
+	}
+	if binding.identity != identityOf(principal) {
+		return false
+	}
+	binding.principal, binding.lastSeen = principal, now
+	return true
+}
+
+// claim binds session id to the principal that created it, overriding a
+// binding made by a request from another identity, and returns the principal
+// of the latest admitted request. It reports false when that request came
+// from another identity.
+func (r *sessionRegistry) claim(id string, creator *auth.Principal) (*auth.Principal, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity := identityOf(creator)
+	binding, ok := r.bindings[id]
+	if !ok {
+		r.sweep(r.now())
+		binding = &sessionBinding{identity: identity, principal: creator, lastSeen: r.now()}
+		r.bindings[id] = binding
+	}
+	if binding.identity != identity {
+		binding.identity, binding.principal = identity, creator
+		return nil, false
+	}
+	return binding.principal, true
+}
+
+// sweep drops bindings idle for longer than sessionIdleTimeout; callers hold mu.
+func (r *sessionRegistry) sweep(now time.Time) {
+	for id, binding := range r.bindings {
+		if now.Sub(binding.lastSeen) > sessionIdleTimeout {
+			delete(r.bindings, id)
+		}
+	}
+}
+
+// requestSession returns the session a transport request belongs to.
+func requestSession(r *http.Request) string {
+	if id := r.Header.Get(sessionHeader); id != "" {
+		return id
+	}
+	return r.URL.Query().Get(sessionParam)
+}
+
+// contextSession returns the id of the session a transport handles a message for.
+func contextSession(ctx context.Context) string {
+	if session, ok := ctx.Value(jsonrpc.SessionKey).(*base.Session); ok && session != nil {
+		return session.Id
+	}
+	return ""
+}
+
+// withSessionOwner rejects requests to a session opened by another principal,
+// answering as if the session did not exist.
+func (s *Server) withSessionOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := requestSession(r); id != "" {
+			principal, _ := auth.PrincipalFromContext(r.Context())
+			if !s.sessions.admit(id, principal) {
+				http.Error(w, "session not found", http.StatusNotFound)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// expired reports whether the token behind principal has expired.
+func expired(principal *auth.Principal, now time.Time) bool {
+	if principal == nil {
+		return false
+	}
+	exp, ok, err := jwt.Claims(principal.Claims).NumericDate("exp")
+	return err != nil || (ok && now.After(exp))
+}
@@ -16,7 +16,8 @@ func (s *Server) RegisterSSE(mux *http.ServeMux, base string) {
 		sse.WithURI(base+"/message:stream"),
 		sse.WithMessageURI(base+"/message:send"),
 	)
-	mux.Handle(base+"/", handler)
-	mux.Handle(base+"/message:stream", handler)
-	mux.Handle(base+"/message:send", handler)
+	limited := s.withStreamLimit(s.withSessionOwner(handler))
+	s.handle(mux, base+"/", limited)
+	s.handle(mux, base+"/message:stream", limited)
+	s.handle(mux, base+"/message:send", limited)
 }
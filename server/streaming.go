@@ -12,5 +12,5 @@ func (s *Server) RegisterStreaming(mux *http.ServeMux, base string) {
         base = "/a2a"
     }
     h := streamable.New(newA2AHandler(s), streamable.WithURI(base))
-    mux.Handle(base, h)
+    s.handle(mux, base, s.withStreamLimit(s.withSessionOwner(h)))
 }
@@ -16,6 +16,19 @@ type taskStore struct {
     pseq int64
     // state transition history per task (internal stub)
     hist map[string][]schema.TaskStateTransition
+    // last observed state and artifact count per task, used to detect changes
+    // even when callers mutate the stored task in place
+    marks map[string]taskMark
+    // onUpdate, if set, is called (outside the lock) after put observes a change
+    onUpdate func(task *schema.Task, statusChanged, artifactsChanged bool)
+    // owners and grants scope task access to callers (see isolation.go)
+    owners map[string]TaskOwner
+    grants map[string][]TaskGrant
+}
+
+type taskMark struct {
+    state     schema.TaskState
+    artifacts int
 }
 
 func newTaskStore() *taskStore {
@@ -23,6 +36,9 @@ func newTaskStore() *taskStore {
         items: map[string]*schema.Task{},
         push:  map[string]map[string]*schema.PushNotificationConfig{},
         hist:  map[string][]schema.TaskStateTransition{},
+        marks: map[string]taskMark{},
+        owners: map[string]TaskOwner{},
+        grants: map[string][]TaskGrant{},
     }
 }
 
@@ -40,6 +56,7 @@ func (t *taskStore) newTask(contextID *string) *schema.Task {
         },
     }
     t.items[id] = task
+    t.marks[id] = taskMark{state: task.Status.State}
     // record initial state
     t.hist[id] = append(t.hist[id], schema.TaskStateTransition{State: task.Status.State, At: task.Status.UpdatedAt})
     return task
@@ -54,7 +71,17 @@ func (t *taskStore) get(id string) (*schema.Task, bool) {
 
 func (t *taskStore) put(task *schema.Task) {
     t.mu.Lock()
-    defer t.mu.Unlock()
+    mark := t.marks[task.ID]
+    statusChanged := mark.state != task.Status.State
+    artifactsChanged := mark.artifacts != len(task.Artifacts)
+    t.marks[task.ID] = taskMark{state: task.Status.State, artifacts: len(task.Artifacts)}
+    onUpdate := t.onUpdate
+    defer func() {
+        t.mu.Unlock()
+        if onUpdate != nil && (statusChanged || artifactsChanged) {
+            onUpdate(task, statusChanged, artifactsChanged)
+        }
+    }()
     if prev, ok := t.items[task.ID]; ok {
         if prev.Status.State != task.Status.State {
             at := task.Status.UpdatedAt
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/auth"
+)
+
+// WellKnownAgentCardPath is the spec-defined discovery location of the public agent card.
+const WellKnownAgentCardPath = "/.well-known/agent-card.json"
+
+// WellKnownJWKSPath publishes the keys verifying signed push notifications.
+const WellKnownJWKSPath = "/.well-known/jwks.json"
+
+// defaultCardCacheControl is used when WithCardCacheControl is not set.
+const defaultCardCacheControl = "public, max-age=300"
+
+// RegisterWellKnown serves the public agent card at WellKnownAgentCardPath.
+// Relative url/additionalInterfaces entries are resolved against the request
+// host (honoring X-Forwarded-Proto/Host/Prefix from WithTrustedProxies), and
+// responses carry an ETag
+// and Cache-Control so clients can revalidate with conditional GETs. The
+// push notification signing keys, if configured, are served at WellKnownJWKSPath.
+func (s *Server) RegisterWellKnown(mux *http.ServeMux) {
+	s.handle(mux, WellKnownAgentCardPath, http.HandlerFunc(s.handleWellKnownCard))
+	s.handle(mux, WellKnownJWKSPath, http.HandlerFunc(s.handleJWKS))
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	keys := s.pushDispatcher.Keys()
+	if keys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	keys.ServeHTTP(w, r)
+}
+
+func (s *Server) handleWellKnownCard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	card := resolveCardURLs(s.Card(), s.requestBaseURL(r))
+	serveCard(w, r, &card, s.cardCacheControl)
+}
+
+// serveCard writes card as JSON with validators, answering conditional GETs with 304.
+func serveCard(w http.ResponseWriter, r *http.Request, card *schema.AgentCard, cacheControl string) {
+	body, err := json.Marshal(card)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	etag := cardETag(body)
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", cacheControl)
+	header.Add("Vary", "Host, X-Forwarded-Host, X-Forwarded-Proto, X-Forwarded-Prefix")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	header.Set("Content-Type", "application/json")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+func cardETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches evaluates an If-None-Match header using weak comparison.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCardURLs returns a copy of card whose relative url and
+// additionalInterfaces entries are made absolute against base.
+func resolveCardURLs(card schema.AgentCard, base string) schema.AgentCard {
+	card.URL = absoluteURL(base, card.URL)
+	if len(card.AdditionalInterfaces) > 0 {
+		interfaces := make([]schema.AgentInterface, len(card.AdditionalInterfaces))
+		for i, iface := range card.AdditionalInterfaces {
+			iface.URL = absoluteURL(base, iface.URL)
+			interfaces[i] = iface
+		}
+		card.AdditionalInterfaces = interfaces
+	}
+	return card
+}
+
+// requestBaseURL derives scheme://host[/prefix] from the request and, when it
+// comes from a trusted proxy, the proxy headers.
+func (s *Server) requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if !auth.FromTrustedProxy(r, s.trustedProxies) {
+		return scheme + "://" + r.Host
+	}
+	if v := strings.ToLower(firstHeaderValue(r, "X-Forwarded-Proto")); v == "http" || v == "https" {
+		scheme = v
+	}
+	host := r.Host
+	if v := firstHeaderValue(r, "X-Forwarded-Host"); v != "" {
+		host = v
+	}
+	prefix := strings.TrimRight(firstHeaderValue(r, "X-Forwarded-Prefix"), "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return scheme + "://" + host + prefix
+}
+
+// firstHeaderValue returns the first comma-separated value of a header (proxies may append).
+func firstHeaderValue(r *http.Request, name string) string {
+	v := r.Header.Get(name)
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v)
+}
+
+func absoluteURL(base, value string) string {
+	if value == "" {
+		return ""
+	}
+	if u, err := url.Parse(value); err == nil && u.IsAbs() {
+		return value
+	}
+	if !strings.HasPrefix(value, "/") {
+		value = "/" + value
+	}
+	return base + value
+}
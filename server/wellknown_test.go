@@ -0,0 +1,119 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/viant/a2a-protocol/jwt"
+	"github.com/viant/a2a-protocol/schema"
+	"github.com/viant/a2a-protocol/server/push"
+)
+
+func TestRegisterWellKnown(t *testing.T) {
+	card := schema.AgentCard{
+		Name:                 "test",
+		URL:                  "/a2a",
+		PreferredTransport:   schema.TransportJSONRPC,
+		AdditionalInterfaces: []schema.AgentInterface{{URL: "/v1", Transport: schema.TransportHTTPJSON}, {URL: "https://static.example.com/a2a", Transport: schema.TransportJSONRPC}},
+	}
+	srv := New(card, WithTrustedProxies("192.0.2.0/24"))
+	mux := http.NewServeMux()
+	srv.RegisterWellKnown(mux)
+
+	req := httptest.NewRequest(http.MethodGet, WellKnownAgentCardPath, nil)
+	req.Host = "internal:8080"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "agents.example.com, proxy.local")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status=%d want 200", rr.Code)
+	}
+	var got schema.AgentCard
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode card: %v", err)
+	}
+	if got.URL != "https://agents.example.com/a2a" {
+		t.Fatalf("url=%s", got.URL)
+	}
+	if got.AdditionalInterfaces[0].URL != "https://agents.example.com/v1" || got.AdditionalInterfaces[1].URL != "https://static.example.com/a2a" {
+		t.Fatalf("additionalInterfaces=%+v", got.AdditionalInterfaces)
+	}
+	if srv.card.URL != "/a2a" {
+		t.Fatalf("server card mutated: %s", srv.card.URL)
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" || rr.Header().Get("Cache-Control") == "" {
+		t.Fatalf("missing validators: %v", rr.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, WellKnownAgentCardPath, nil)
+	req.Host = "internal:8080"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "agents.example.com")
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Fatalf("conditional GET status=%d body=%q, want 304", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, WellKnownAgentCardPath, nil)
+	req.Host = "other.example.com"
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Fatalf("different host must produce a new representation, status=%d", rr.Code)
+	}
+
+	// forwarded headers only count from trusted proxies, and only for http(s)
+	testCases := []struct {
+		description string
+		remoteAddr  string
+		proto       string
+		expectURL   string
+	}{
+		{description: "trusted proxy", remoteAddr: "192.0.2.7:4000", proto: "https", expectURL: "https://agents.example.com/a2a"},
+		{description: "untrusted peer", remoteAddr: "203.0.113.9:4000", proto: "https", expectURL: "http://internal:8080/a2a"},
+		{description: "unsupported scheme", remoteAddr: "192.0.2.7:4000", proto: "javascript", expectURL: "http://agents.example.com/a2a"},
+	}
+	for _, testCase := range testCases {
+		req = httptest.NewRequest(http.MethodGet, WellKnownAgentCardPath, nil)
+		req.RemoteAddr = testCase.remoteAddr
+		req.Host = "internal:8080"
+		req.Header.Set("X-Forwarded-Proto", testCase.proto)
+		req.Header.Set("X-Forwarded-Host", "agents.example.com")
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		var actual schema.AgentCard
+		if err := json.Unmarshal(rr.Body.Bytes(), &actual); err != nil || actual.URL != testCase.expectURL {
+			t.Fatalf("%s: url=%s err=%v", testCase.description, actual.URL, err)
+		}
+	}
+}
+
+func TestRegisterWellKnown_JWKS(t *testing.T) {
+	mux := http.NewServeMux()
+	New(schema.AgentCard{Name: "test"}).RegisterWellKnown(mux)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, WellKnownJWKSPath, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status=%d, want 404 without signing keys", rr.Code)
+	}
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keys, _ := jwt.NewKeySet(jwt.SigningKey{ID: "k1", Key: key})
+	mux = http.NewServeMux()
+	New(schema.AgentCard{Name: "test"}, WithPushDispatcher(push.New(push.WithSigningKeys(keys)))).RegisterWellKnown(mux)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, WellKnownJWKSPath, nil))
+	var set jwt.JWKS
+	if err := json.Unmarshal(rr.Body.Bytes(), &set); err != nil || len(set.Keys) != 1 || set.Keys[0].Kid != "k1" || set.Keys[0].Alg != jwt.EdDSA {
+		t.Fatalf("jwks=%s err=%v", rr.Body.String(), err)
+	}
+}